@@ -0,0 +1,134 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// duplicateRegexp matches the error Deluge returns when adding a torrent it already has, like:
+// "AddTorrentError: Torrent already in session (0123456789abcdef0123456789abcdef01234567)."
+var duplicateRegexp = regexp.MustCompile(`already (?:in session|being added) \(([0-9a-fA-F]{40})\)`)
+
+// AddOptions are the options applied to a torrent as it is added, by every add method.
+// Empty values use the daemon's defaults. Speeds are in KiB/s; use -1 for unlimited.
+// FilePriorities has one entry per file, in file order: 0 skips a file, 1 is normal,
+// and 7 is the highest priority.
+//
+// Deluge cannot label a torrent while adding it, so Label is applied after the add
+// succeeds, when the hash is known. A missing label returns ErrUnknownLabel, unless
+// Config.AutoCreateLabels is set; the torrent is still added.
+type AddOptions struct {
+	DownloadLocation  string
+	MoveCompletedPath string // Move completed downloads here. Empty does not move them.
+	AddPaused         bool
+	MaxDownloadSpeed  float64
+	MaxUploadSpeed    float64
+	FilePriorities    []int
+	Label             string
+}
+
+// Options returns the options dict accepted by the core.add_torrent_* methods, without Label.
+// A nil AddOptions is empty. The delugerpc client sends the same dict to the daemon.
+func (a *AddOptions) Options() map[string]interface{} {
+	opts := make(map[string]interface{})
+	if a == nil {
+		return opts
+	}
+
+	if a.DownloadLocation != "" {
+		opts["download_location"] = a.DownloadLocation
+	}
+
+	if a.MoveCompletedPath != "" {
+		opts["move_completed"] = true
+		opts["move_completed_path"] = a.MoveCompletedPath
+	}
+
+	if a.AddPaused {
+		opts["add_paused"] = true
+	}
+
+	if a.MaxDownloadSpeed != 0 {
+		opts["max_download_speed"] = a.MaxDownloadSpeed
+	}
+
+	if a.MaxUploadSpeed != 0 {
+		opts["max_upload_speed"] = a.MaxUploadSpeed
+	}
+
+	if len(a.FilePriorities) > 0 {
+		opts["file_priorities"] = a.FilePriorities
+	}
+
+	return opts
+}
+
+// labelAdded applies the Label from opts to a newly added torrent.
+func (d *Deluge) labelAdded(ctx context.Context, hash string, opts *AddOptions) error {
+	if opts == nil || opts.Label == "" || hash == "" {
+		return nil
+	}
+
+	return d.SetTorrentLabel(ctx, hash, opts.Label)
+}
+
+// AddTorrentMagnet adds a torrent from a magnet link, and returns its hash. opts may be nil.
+// Adding a torrent Deluge already has returns the existing hash with ErrAlreadyAdded.
+func (d *Deluge) AddTorrentMagnet(ctx context.Context, magnetURI string, opts *AddOptions) (string, error) {
+	return d.addTorrent(ctx, AddMagnet, opts, magnetURI)
+}
+
+// AddTorrentURL has Deluge download a .torrent file from url and add it, and returns its hash.
+// opts may be nil. Deluge waits for the download, but some versions return no result when the
+// download or add finishes asynchronously; the hash is empty then, with no error. Use Exists
+// or GetXfersMatching later to find the torrent. A duplicate returns ErrAlreadyAdded.
+func (d *Deluge) AddTorrentURL(ctx context.Context, url string, opts *AddOptions) (string, error) {
+	return d.addTorrent(ctx, AddTorrentURL, opts, url)
+}
+
+// AddTorrentFile adds a torrent from the contents of a .torrent file, and returns its hash.
+// filename is only used for display and logs. opts may be nil. Pass the raw file contents;
+// they are base64 encoded while the request is written. A duplicate returns ErrAlreadyAdded.
+func (d *Deluge) AddTorrentFile(ctx context.Context, filename string, contents []byte, opts *AddOptions) (string, error) {
+	if len(contents) == 0 {
+		return "", fmt.Errorf("%w: empty torrent file %s", ErrInvalidValue, filename)
+	}
+
+	return d.addTorrent(ctx, AddTorrentFile, opts, filename, torrentFile(contents))
+}
+
+// addTorrent calls one of the core.add_torrent_* methods with params followed by the
+// options from opts, applies the label from opts, and returns the new torrent's hash.
+// Pass .torrent file contents in params as a torrentFile; the request body base64 encodes
+// it as the body is sent.
+// Adding a torrent Deluge already has returns the existing hash with ErrAlreadyAdded.
+// Deluge 1.x does not report duplicates; it returns no hash, and no error.
+func (d *Deluge) addTorrent(ctx context.Context, method string, opts *AddOptions, params ...interface{}) (string, error) {
+	response, err := d.Get(ctx, method, append(params, opts.Options()))
+	if err != nil {
+		if hash := DuplicateHash(err.Error()); hash != "" {
+			return hash, fmt.Errorf("%w: %s", ErrAlreadyAdded, hash)
+		}
+
+		return "", fmt.Errorf("get(%s): %w", method, err)
+	}
+
+	var hash string
+	if err := decodeResult(response.Result, &hash); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(hash): %w", err)
+	}
+
+	return hash, d.labelAdded(ctx, hash, opts)
+}
+
+// DuplicateHash returns the existing torrent hash from a duplicate-torrent error message, or an
+// empty string. It matches the messages from both the web UI and the daemon.
+func DuplicateHash(msg string) string {
+	if match := duplicateRegexp.FindStringSubmatch(msg); len(match) > 1 {
+		return strings.ToLower(match[1])
+	}
+
+	return ""
+}
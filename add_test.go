@@ -0,0 +1,53 @@
+package deluge
+
+import "testing"
+
+func TestDuplicateHash(t *testing.T) {
+	t.Parallel()
+
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{
+			name: "2.x add_torrent_file",
+			msg:  "AddTorrentError: Torrent already in session (" + hash + ").",
+			want: hash,
+		},
+		{
+			name: "2.x magnet being added",
+			msg:  "AddTorrentError: Torrent already being added (" + hash + ").",
+			want: hash,
+		},
+		{
+			name: "2.x web.add_torrents",
+			msg:  `"Torrent already in session (` + hash + `)."`,
+			want: hash,
+		},
+		{
+			name: "upper case hash",
+			msg:  "AddTorrentError: Torrent already in session (0123456789ABCDEF0123456789ABCDEF01234567).",
+			want: hash,
+		},
+		{
+			// Deluge 1.x logs libtorrent's error and returns a null result, without the hash.
+			name: "1.x libtorrent",
+			msg:  "torrent already exists in session",
+			want: "",
+		},
+		{
+			name: "other error",
+			msg:  "AddTorrentError: Unable to add torrent, decoding filedump failed",
+			want: "",
+		},
+	}
+
+	for _, test := range tests {
+		if got := DuplicateHash(test.msg); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// AutoAddDir is a watch folder in the AutoAdd plugin.
+// Label, DownloadLocation, MoveCompleted and AddPaused only apply
+// to added torrents when their matching toggle is also true.
+type AutoAddDir struct {
+	Path                   string `json:"path,omitempty"`
+	AbsPath                string `json:"abspath,omitempty"`
+	Enabled                bool   `json:"enabled"`
+	Label                  string `json:"label,omitempty"`
+	LabelToggle            bool   `json:"label_toggle"`
+	DownloadLocation       string `json:"download_location,omitempty"`
+	DownloadLocationToggle bool   `json:"download_location_toggle"`
+	MoveCompleted          bool   `json:"move_completed"`
+	MoveCompletedPath      string `json:"move_completed_path,omitempty"`
+	MoveCompletedToggle    bool   `json:"move_completed_toggle"`
+	AddPaused              bool   `json:"add_paused"`
+	AddPausedToggle        bool   `json:"add_paused_toggle"`
+}
+
+// GetAutoAddWatchDirs returns the AutoAdd plugin's watch folders, keyed by their ID.
+// Returns ErrPluginNotInstalled if the AutoAdd plugin is not available.
+func (d *Deluge) GetAutoAddWatchDirs(ctx context.Context) (map[string]AutoAddDir, error) {
+	response, err := d.Get(ctx, GetWatchDirs, []string{})
+	if err != nil {
+		return nil, pluginError("AutoAdd", fmt.Errorf("get(GetWatchDirs): %w", err))
+	}
+
+	dirs := make(map[string]AutoAddDir)
+	if err := decodeResult(response.Result, &dirs); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(dirs): %w", err)
+	}
+
+	return dirs, nil
+}
+
+// SetAutoAddWatchDir updates the options for an existing AutoAdd watch folder. The plugin
+// keeps options that are not sent, but every bool is always sent, so a false field turns
+// that option off. Modify the value from GetAutoAddWatchDirs instead of a new one. An empty
+// Path or string option is not sent, and keeps its current value.
+// Returns ErrPluginNotInstalled if the AutoAdd plugin is not available.
+func (d *Deluge) SetAutoAddWatchDir(ctx context.Context, id string, cfg AutoAddDir) error {
+	if _, err := d.Get(ctx, SetWatchDir, []interface{}{id, cfg}); err != nil {
+		return pluginError("AutoAdd", fmt.Errorf("get(SetWatchDir): %w", err))
+	}
+
+	return nil
+}
+
+// AddAutoAddWatchDir creates an AutoAdd watch folder, and returns its ID.
+// The path must exist on the Deluge server. Returns ErrPluginNotInstalled
+// if the AutoAdd plugin is not available.
+func (d *Deluge) AddAutoAddWatchDir(ctx context.Context, cfg AutoAddDir) (string, error) {
+	response, err := d.Get(ctx, AddWatchDir, []interface{}{cfg})
+	if err != nil {
+		return "", pluginError("AutoAdd", fmt.Errorf("get(AddWatchDir): %w", err))
+	}
+
+	var id json.Number
+	if err := decodeResult(response.Result, &id); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(id): %w", err)
+	}
+
+	return id.String(), nil
+}
+
+// RemoveAutoAddWatchDir deletes an AutoAdd watch folder. Files in the folder are not touched.
+func (d *Deluge) RemoveAutoAddWatchDir(ctx context.Context, id string) error {
+	return d.autoAddWatchDir(ctx, DelWatchDir, id)
+}
+
+// EnableAutoAddWatchDir starts watching an AutoAdd watch folder.
+func (d *Deluge) EnableAutoAddWatchDir(ctx context.Context, id string) error {
+	return d.autoAddWatchDir(ctx, EnableWatchDir, id)
+}
+
+// DisableAutoAddWatchDir stops watching an AutoAdd watch folder, without deleting it.
+func (d *Deluge) DisableAutoAddWatchDir(ctx context.Context, id string) error {
+	return d.autoAddWatchDir(ctx, StopWatchDir, id)
+}
+
+// autoAddWatchDir calls an AutoAdd method that takes only a watch folder ID.
+func (d *Deluge) autoAddWatchDir(ctx context.Context, method, id string) error {
+	if _, err := d.Get(ctx, method, []string{id}); err != nil {
+		return pluginError("AutoAdd", fmt.Errorf("get(%s): %w", method, err))
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+)
+
+// BlocklistConfig is the Blocklist plugin's configuration.
+// CheckAfterDays is how old the list may get before it is downloaded again.
+// ListCompression is "", "GZip", "Zip" or "BZip2"; empty detects it from the file.
+// ListType is "", "Emule", "SafePeer" or "PeerGuardian"; empty detects it from the file.
+// LastUpdate (a Unix time) and ListSize describe the last imported list.
+type BlocklistConfig struct {
+	URL             string   `json:"url"`
+	LoadOnStart     bool     `json:"load_on_start"`
+	CheckAfterDays  int      `json:"check_after_days"`
+	ListCompression string   `json:"list_compression"`
+	ListType        string   `json:"list_type"`
+	LastUpdate      float64  `json:"last_update"`
+	ListSize        int64    `json:"list_size"`
+	Timeout         int      `json:"timeout"`
+	TryTimes        int      `json:"try_times"`
+	Whitelisted     []string `json:"whitelisted"`
+}
+
+// BlocklistStatus is the Blocklist plugin's import state.
+// State is Idle, Downloading or Importing. FileDate is the Unix time of the last import.
+type BlocklistStatus struct {
+	State        string   `json:"state"`
+	NumBlocked   int64    `json:"num_blocked"`
+	NumWhited    int64    `json:"num_whited"`
+	UpToDate     bool     `json:"up_to_date"`
+	FileProgress float64  `json:"file_progress"`
+	FileSize     int64    `json:"file_size"`
+	FileDate     float64  `json:"file_date"`
+	FileType     string   `json:"file_type"`
+	FileURL      string   `json:"file_url"`
+	Whitelisted  []string `json:"whitelisted"`
+}
+
+// GetBlocklistConfig returns the Blocklist plugin's configuration.
+// Returns ErrPluginNotInstalled if the Blocklist plugin is not available.
+func (d *Deluge) GetBlocklistConfig(ctx context.Context) (*BlocklistConfig, error) {
+	response, err := d.Get(ctx, GetBlocklist, []string{})
+	if err != nil {
+		return nil, pluginError("Blocklist", fmt.Errorf("get(GetBlocklist): %w", err))
+	}
+
+	var config BlocklistConfig
+	if err := decodeResult(response.Result, &config); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(config): %w", err)
+	}
+
+	return &config, nil
+}
+
+// SetBlocklistConfig saves the Blocklist plugin's settings. LastUpdate and ListSize belong
+// to the plugin, which updates them as it imports, so they are not sent. Removing addresses
+// from Whitelisted makes the plugin import the list again; other changes apply at the next
+// import, or right away with CheckBlocklistImport.
+func (d *Deluge) SetBlocklistConfig(ctx context.Context, config *BlocklistConfig) error {
+	settings := map[string]interface{}{
+		"url":              config.URL,
+		"load_on_start":    config.LoadOnStart,
+		"check_after_days": config.CheckAfterDays,
+		"list_compression": config.ListCompression,
+		"list_type":        config.ListType,
+		"timeout":          config.Timeout,
+		"try_times":        config.TryTimes,
+		"whitelisted":      config.Whitelisted,
+	}
+
+	if config.Whitelisted == nil {
+		settings["whitelisted"] = []string{} // The plugin compares this to a list; never send null.
+	}
+
+	if _, err := d.Get(ctx, SetBlocklist, []interface{}{settings}); err != nil {
+		return pluginError("Blocklist", fmt.Errorf("get(SetBlocklist): %w", err))
+	}
+
+	return nil
+}
+
+// CheckBlocklistImport downloads and imports the blocklist if it is out of date, or always if force is true.
+// The import runs in the background on the server; watch it with GetBlocklistStatus.
+func (d *Deluge) CheckBlocklistImport(ctx context.Context, force bool) error {
+	if _, err := d.Get(ctx, CheckBlocklist, []interface{}{force}); err != nil {
+		return pluginError("Blocklist", fmt.Errorf("get(CheckBlocklist): %w", err))
+	}
+
+	return nil
+}
+
+// GetBlocklistStatus returns the Blocklist plugin's import state and counts.
+func (d *Deluge) GetBlocklistStatus(ctx context.Context) (*BlocklistStatus, error) {
+	response, err := d.Get(ctx, BlocklistStat, []string{})
+	if err != nil {
+		return nil, pluginError("Blocklist", fmt.Errorf("get(BlocklistStat): %w", err))
+	}
+
+	var status BlocklistStatus
+	if err := decodeResult(response.Result, &status); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	return &status, nil
+}
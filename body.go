@@ -0,0 +1,145 @@
+package deluge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// base64Chunk is how much of a torrentFile is encoded at a time. It is a multiple
+// of 3, so every chunk but the last encodes without padding.
+const base64Chunk = 3 * 1024
+
+// torrentFile is .torrent file contents in request params. It is sent as a base64 string,
+// encoded a chunk at a time while the request body is read, so the encoded file is never
+// held in memory. json.Marshal would build the whole encoded request, then copy it.
+type torrentFile []byte
+
+// bodyPart is a piece of a request body: JSON text, or a torrentFile to base64 encode.
+type bodyPart struct {
+	data []byte
+	file bool
+}
+
+// requestBody is a JSON-RPC request body that can be read more than once, for retries.
+type requestBody []bodyPart
+
+// newRequestBody builds the body for a request. params without a torrentFile are
+// marshaled as one piece of JSON, in the same shape.
+func newRequestBody(id int64, method string, params interface{}) (requestBody, error) {
+	list, _ := params.([]interface{})
+	if !hasTorrentFile(list) {
+		data, err := json.Marshal(map[string]interface{}{"method": method, "id": id, "params": params})
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal(params): %w", err)
+		}
+
+		return requestBody{{data: data}}, nil
+	}
+
+	name, err := json.Marshal(method)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal(method): %w", err)
+	}
+
+	var (
+		body requestBody
+		text = bytes.NewBufferString(fmt.Sprintf(`{"id":%d,"method":%s,"params":[`, id, name))
+	)
+
+	for idx, param := range list {
+		if idx > 0 {
+			text.WriteByte(',')
+		}
+
+		if file, ok := param.(torrentFile); ok {
+			text.WriteByte('"')
+			body = append(body, bodyPart{data: text.Bytes()}, bodyPart{data: file, file: true})
+			text = bytes.NewBufferString(`"`)
+
+			continue
+		}
+
+		data, err := json.Marshal(param)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal(params): %w", err)
+		}
+
+		text.Write(data)
+	}
+
+	text.WriteString("]}")
+
+	return append(body, bodyPart{data: text.Bytes()}), nil
+}
+
+func hasTorrentFile(params []interface{}) bool {
+	for _, param := range params {
+		if _, ok := param.(torrentFile); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// size returns the length of the body, with torrent files base64 encoded.
+func (b requestBody) size() int64 {
+	var total int
+
+	for _, part := range b {
+		if part.file {
+			total += base64.StdEncoding.EncodedLen(len(part.data))
+		} else {
+			total += len(part.data)
+		}
+	}
+
+	return int64(total)
+}
+
+// reader returns a new reader for the whole body.
+func (b requestBody) reader() io.ReadCloser {
+	readers := make([]io.Reader, len(b))
+
+	for idx, part := range b {
+		if part.file {
+			readers[idx] = &base64Reader{src: part.data}
+		} else {
+			readers[idx] = bytes.NewReader(part.data)
+		}
+	}
+
+	return io.NopCloser(io.MultiReader(readers...))
+}
+
+// base64Reader reads src as base64, encoding one chunk at a time into buf.
+type base64Reader struct {
+	src []byte
+	out []byte
+	buf [base64Chunk / 3 * 4]byte
+}
+
+func (r *base64Reader) Read(p []byte) (int, error) {
+	if len(r.out) == 0 {
+		if len(r.src) == 0 {
+			return 0, io.EOF
+		}
+
+		size := len(r.src)
+		if size > base64Chunk {
+			size = base64Chunk
+		}
+
+		r.out = r.buf[:base64.StdEncoding.EncodedLen(size)]
+		base64.StdEncoding.Encode(r.out, r.src[:size])
+		r.src = r.src[size:]
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+
+	return n, nil
+}
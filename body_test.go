@@ -0,0 +1,82 @@
+package deluge
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestRequestBody(t *testing.T) {
+	t.Parallel()
+
+	opts := (&AddOptions{DownloadLocation: "/downloads", AddPaused: true}).Options()
+
+	for _, size := range []int{1, 2, base64Chunk - 1, base64Chunk, base64Chunk + 1, 5*base64Chunk + 2} {
+		contents := bytes.Repeat([]byte{0, 'd', 0xff}, size/3+1)[:size]
+
+		// The streamed body must match what json.Marshal makes of the raw contents.
+		want, err := json.Marshal(map[string]interface{}{
+			"id": 7, "method": AddTorrentFile, "params": []interface{}{"a.torrent", contents, opts},
+		})
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+
+		body, err := newRequestBody(7, AddTorrentFile, []interface{}{"a.torrent", torrentFile(contents), opts})
+		if err != nil {
+			t.Fatalf("newRequestBody: %v", err)
+		}
+
+		if body.size() != int64(len(want)) {
+			t.Errorf("%d bytes: size: got %d, want %d", size, body.size(), len(want))
+		}
+
+		// Read it twice, like a retried request does.
+		for attempt := 1; attempt <= 2; attempt++ {
+			got, err := io.ReadAll(body.reader())
+			if err != nil {
+				t.Fatalf("reading body: %v", err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("%d bytes, read %d:\ngot  %.200s\nwant %.200s", size, attempt, got, want)
+			}
+		}
+	}
+}
+
+// BenchmarkAddTorrentFileBody compares building and reading an add_torrent_file request
+// body with json.Marshal, as DelReq did before torrentFile, against the streamed body.
+func BenchmarkAddTorrentFileBody(b *testing.B) {
+	contents := bytes.Repeat([]byte("d4:infod6:lengthi1ee"), 50000) // About 1MB.
+	opts := (&AddOptions{AddPaused: true}).Options()
+
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			data, err := json.Marshal(map[string]interface{}{
+				"id": 1, "method": AddTorrentFile, "params": []interface{}{"a.torrent", contents, opts},
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			_, _ = io.Copy(io.Discard, bytes.NewBuffer(data))
+		}
+	})
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			body, err := newRequestBody(1, AddTorrentFile, []interface{}{"a.torrent", torrentFile(contents), opts})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			_, _ = io.Copy(io.Discard, body.reader())
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package deluge
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// bulkPairLen is the length of each [hash, message] pair in a bulk method result.
+const bulkPairLen = 2
+
+// ParseBulkResult decodes the result of a bulk method into a map of hash to error.
+// The delugerpc client uses it too, so both clients report bulk failures alike.
+// Only failed hashes are in the map. Deluge returns a few shapes:
+//   - null or an empty list: everything succeeded.
+//   - a list of [hash, message] pairs: each pair is a failure.
+//   - a dict of hash to message: each non-empty message is a failure.
+func ParseBulkResult(raw json.RawMessage) map[string]error {
+	failed := make(map[string]error)
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return failed
+	}
+
+	pairs := [][]interface{}{}
+	if err := json.Unmarshal(raw, &pairs); err == nil {
+		for _, pair := range pairs {
+			if len(pair) < bulkPairLen {
+				continue
+			}
+
+			if hash, ok := pair[0].(string); ok {
+				failed[hash] = fmt.Errorf("%w: %v", ErrDelugeError, pair[1])
+			}
+		}
+
+		return failed
+	}
+
+	messages := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &messages); err == nil {
+		for hash, msg := range messages {
+			switch val := msg.(type) {
+			case nil, bool:
+				if val == nil || val == true {
+					continue
+				}
+			case string:
+				if val == "" {
+					continue
+				}
+			}
+
+			failed[hash] = fmt.Errorf("%w: %v", ErrDelugeError, msg)
+		}
+	}
+
+	return failed
+}
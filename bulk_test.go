@@ -0,0 +1,78 @@
+package deluge
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestParseBulkResult(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hash1 = "1111111111111111111111111111111111111111"
+		hash2 = "2222222222222222222222222222222222222222"
+	)
+
+	tests := []struct {
+		name   string
+		raw    string
+		failed []string
+	}{
+		{name: "missing", raw: ``},
+		{name: "null", raw: `null`},
+		{name: "empty list", raw: `[]`},
+		{name: "empty dict", raw: `{}`},
+		{
+			name:   "list of pairs",
+			raw:    `[["` + hash1 + `", "InvalidTorrentError: torrent_id not in session"]]`,
+			failed: []string{hash1},
+		},
+		{
+			name:   "short pair",
+			raw:    `[["` + hash1 + `"], ["` + hash2 + `", "error"]]`,
+			failed: []string{hash2},
+		},
+		{
+			name:   "dict of messages",
+			raw:    `{"` + hash1 + `": "error", "` + hash2 + `": ""}`,
+			failed: []string{hash1},
+		},
+		{
+			name:   "dict of booleans",
+			raw:    `{"` + hash1 + `": false, "` + hash2 + `": true}`,
+			failed: []string{hash1},
+		},
+		{
+			name: "dict of nulls",
+			raw:  `{"` + hash1 + `": null}`,
+		},
+	}
+
+	for _, test := range tests {
+		got := ParseBulkResult(json.RawMessage(test.raw))
+
+		hashes := make([]string, 0, len(got))
+		for hash, err := range got {
+			hashes = append(hashes, hash)
+
+			if !errors.Is(err, ErrDelugeError) {
+				t.Errorf("%s: %s: got %v, want %v", test.name, hash, err, ErrDelugeError)
+			}
+		}
+
+		sort.Strings(hashes)
+
+		if len(hashes) != len(test.failed) {
+			t.Errorf("%s: failed: got %v, want %v", test.name, hashes, test.failed)
+			continue
+		}
+
+		for i := range hashes {
+			if hashes[i] != test.failed[i] {
+				t.Errorf("%s: failed: got %v, want %v", test.name, hashes, test.failed)
+			}
+		}
+	}
+}
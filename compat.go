@@ -0,0 +1,146 @@
+package deluge
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ByteRate is a transfer rate in bytes per second.
+type ByteRate int64
+
+// String turns a rate into a human-readable string, like "1.2 MiB/s".
+func (b ByteRate) String() string {
+	const unit = 1024
+
+	if b < unit {
+		return strconv.FormatInt(int64(b), 10) + " B/s"
+	}
+
+	div, exp := int64(unit), 0
+	for n := int64(b) / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB/s", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// DownRate returns the download payload rate as a ByteRate.
+func (x *XferStatusCompat) DownRate() ByteRate {
+	return ByteRate(x.DownloadPayloadRate)
+}
+
+// UpRate returns the upload payload rate as a ByteRate.
+func (x *XferStatusCompat) UpRate() ByteRate {
+	return ByteRate(x.UploadPayloadRate)
+}
+
+// Peer is a single peer connected to a torrent.
+type Peer struct {
+	IP        string  `json:"ip"`
+	Client    string  `json:"client"`
+	Country   string  `json:"country"`
+	DownSpeed float64 `json:"down_speed"`
+	UpSpeed   float64 `json:"up_speed"`
+	Progress  float64 `json:"progress"`
+	Seed      Bool    `json:"seed"` // Deluge sends the peer's seed flag bit: 1024 or 0.
+}
+
+// MoveCompletedEnabled returns true if the torrent moves its data when it completes.
+// Deluge 1.x populates move_on_completed while 2.x populates move_completed; this checks both.
+func (x *XferStatusCompat) MoveCompletedEnabled() bool {
+	return bool(x.MoveCompleted) || bool(x.MoveOnCompleted)
+}
+
+// MoveCompletedLocation returns the move completed path from whichever field the server populated.
+// Deluge 1.x populates move_on_completed_path while 2.x populates move_completed_path.
+// This is not named MoveCompletedPath because that is already a field on the struct.
+func (x *XferStatusCompat) MoveCompletedLocation() string {
+	if x.MoveCompletedPath != "" {
+		return x.MoveCompletedPath
+	}
+
+	return x.MoveOnCompletedPath
+}
+
+// Flatten returns a flat map of the most useful transfer data, suitable for time-series databases or logs.
+// The keys and value types are stable; new keys may be added, but existing keys will not change:
+//   - strings: hash, name, state, label, tracker_host, save_path
+//   - int64 bytes/sec: download_rate, upload_rate
+//   - int64 bytes: total_size, total_done, total_uploaded, total_wanted
+//   - int64 counts: num_seeds, num_peers, total_seeds, total_peers, queue
+//   - int64 seconds: eta, active_time, seeding_time
+//   - float64: progress (0 to 1), ratio
+//   - bool: is_finished, paused, private
+//   - RFC3339 strings in UTC: time_added, completed_time (empty if never completed)
+func (x *XferStatusCompat) Flatten() map[string]interface{} {
+	const percent = 100
+
+	eta, _ := x.Eta.Float64()
+
+	savePath := x.DownloadLocation
+	if savePath == "" {
+		savePath = x.SavePath // Deluge 1.x
+	}
+
+	return map[string]interface{}{
+		"hash":           x.Hash,
+		"name":           x.Name,
+		"state":          x.State,
+		"label":          x.Label,
+		"tracker_host":   x.TrackerHost,
+		"save_path":      savePath,
+		"download_rate":  int64(x.DownloadPayloadRate),
+		"upload_rate":    int64(x.UploadPayloadRate),
+		"total_size":     int64(x.TotalSize),
+		"total_done":     int64(x.TotalDone),
+		"total_uploaded": int64(x.TotalUploaded),
+		"total_wanted":   int64(x.TotalWanted),
+		"num_seeds":      x.NumSeeds,
+		"num_peers":      x.NumPeers,
+		"total_seeds":    int64(x.TotalSeeds),
+		"total_peers":    x.TotalPeers,
+		"queue":          x.Queue,
+		"eta":            int64(eta),
+		"active_time":    int64(x.ActiveTime),
+		"seeding_time":   int64(x.SeedingTime),
+		"progress":       x.Progress / percent,
+		"ratio":          x.Ratio,
+		"is_finished":    x.IsFinished,
+		"paused":         x.Paused,
+		"private":        x.Private,
+		"time_added":     unixTime(x.TimeAdded),
+		"completed_time": unixTime(x.CompletedTime),
+	}
+}
+
+// unixTime formats a Deluge timestamp (float seconds) as RFC3339, or returns an empty string for zero.
+func unixTime(seconds float64) string {
+	if seconds <= 0 {
+		return ""
+	}
+
+	return time.Unix(int64(seconds), 0).UTC().Format(time.RFC3339)
+}
+
+// QueuePosition returns the torrent's 0-based position in the queue, or -1 if it is not queued (seeding torrents).
+func (x *XferStatusCompat) QueuePosition() int {
+	if x.Queue < 0 {
+		return -1
+	}
+
+	return int(x.Queue)
+}
+
+// SeedCounts returns the number of seeds the torrent is connected to, and the number of seeds in the swarm.
+// It is not named Seeds to match PeerCounts, which cannot be named Peers because of the Peers field.
+func (x *XferStatusCompat) SeedCounts() (connected, total int64) {
+	return x.NumSeeds, int64(math.Round(x.TotalSeeds))
+}
+
+// PeerCounts returns the number of peers the torrent is connected to, and the number of peers in the swarm.
+func (x *XferStatusCompat) PeerCounts() (connected, total int64) {
+	return x.NumPeers, x.TotalPeers
+}
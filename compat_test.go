@@ -0,0 +1,112 @@
+package deluge
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMoveCompleted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		payload string
+		enabled bool
+		path    string
+	}{
+		{
+			name:    "1.x enabled",
+			payload: `{"move_on_completed": true, "move_on_completed_path": "/done"}`,
+			enabled: true,
+			path:    "/done",
+		},
+		{
+			name:    "1.x disabled",
+			payload: `{"move_on_completed": false, "move_on_completed_path": "/done"}`,
+			enabled: false,
+			path:    "/done",
+		},
+		{
+			name:    "2.x enabled",
+			payload: `{"move_completed": true, "move_completed_path": "/done"}`,
+			enabled: true,
+			path:    "/done",
+		},
+		{
+			name:    "2.x disabled",
+			payload: `{"move_completed": false, "move_completed_path": ""}`,
+			enabled: false,
+			path:    "",
+		},
+		{
+			name:    "2.x wins over 1.x",
+			payload: `{"move_completed": true, "move_completed_path": "/new", "move_on_completed_path": "/old"}`,
+			enabled: true,
+			path:    "/new",
+		},
+	}
+
+	for _, test := range tests {
+		var xfer XferStatusCompat
+		if err := json.Unmarshal([]byte(test.payload), &xfer); err != nil {
+			t.Fatalf("%s: json.Unmarshal: %v", test.name, err)
+		}
+
+		if got := xfer.MoveCompletedEnabled(); got != test.enabled {
+			t.Errorf("%s: MoveCompletedEnabled: got %v, want %v", test.name, got, test.enabled)
+		}
+
+		if got := xfer.MoveCompletedLocation(); got != test.path {
+			t.Errorf("%s: MoveCompletedLocation: got %q, want %q", test.name, got, test.path)
+		}
+	}
+}
+
+func TestPeers(t *testing.T) {
+	t.Parallel()
+
+	// Both versions send seed as peer.flags & peer.seed, which is 1024 for a seed, or 0.
+	tests := []struct {
+		name    string
+		payload string
+		want    []Peer
+	}{
+		{
+			name: "1.x",
+			payload: `{"peers": [
+				{"client": "Transmission 2.94", "country": "US", "down_speed": 2048, "ip": "10.0.0.1:51413",
+				 "progress": 1.0, "seed": 1024, "up_speed": 0},
+				{"client": "libtorrent 0.16.0.0", "country": "  ", "down_speed": 0, "ip": "10.0.0.2:6881",
+				 "progress": 0.25, "seed": 0, "up_speed": 512}]}`,
+			want: []Peer{
+				{IP: "10.0.0.1:51413", Client: "Transmission 2.94", Country: "US", DownSpeed: 2048, Progress: 1, Seed: true},
+				{IP: "10.0.0.2:6881", Client: "libtorrent 0.16.0.0", Country: "  ", UpSpeed: 512, Progress: 0.25},
+			},
+		},
+		{
+			name: "2.x",
+			payload: `{"peers": [
+				{"client": "qBittorrent 4.3.9", "country": "DE", "down_speed": 4096, "ip": "[2001:db8::1]:6881",
+				 "progress": 1.0, "seed": 1024, "up_speed": 128},
+				{"client": "Deluge 2.0.3", "country": "", "down_speed": 0, "ip": "10.0.0.3:58846",
+				 "progress": 0.0, "seed": 0, "up_speed": 0}]}`,
+			want: []Peer{
+				{IP: "[2001:db8::1]:6881", Client: "qBittorrent 4.3.9", Country: "DE", DownSpeed: 4096, UpSpeed: 128,
+					Progress: 1, Seed: true},
+				{IP: "10.0.0.3:58846", Client: "Deluge 2.0.3"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		var xfer XferStatusCompat
+		if err := json.Unmarshal([]byte(test.payload), &xfer); err != nil {
+			t.Fatalf("%s: json.Unmarshal: %v", test.name, err)
+		}
+
+		if !reflect.DeepEqual(xfer.Peers, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, xfer.Peers, test.want)
+		}
+	}
+}
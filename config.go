@@ -4,30 +4,145 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // Deluge WebUI methods.
 const (
 	AuthLogin      = "auth.login"
+	CheckSession   = "auth.check_session"
 	AddMagnet      = "core.add_torrent_magnet"
 	AddTorrentURL  = "core.add_torrent_url"
 	AddTorrentFile = "core.add_torrent_file"
 	GetTorrentStat = "core.get_torrent_status"
+	GetTorrentData = "core.get_torrent_file"
 	GetAllTorrents = "core.get_torrents_status"
+	GetSession     = "core.get_session_state"
+	GetSessionStat = "core.get_session_status"
 	HostStatus     = "web.get_host_status"
 	GeHosts        = "web.get_hosts"
+	GetPlugins     = "core.get_available_plugins"
+	GetEnabled     = "core.get_enabled_plugins"
+	EnablePlugin   = "core.enable_plugin"
+	MoveStorage    = "core.move_storage"
+	GetLabelOpts   = "label.get_options"
+	SetLabelOpts   = "label.set_options"
+	GetLabels      = "label.get_labels"
+	AddLabel       = "label.add"
+	SetLabel       = "label.set_torrent"
+	RemoveTorrent  = "core.remove_torrent"
+	RemoveTorrents = "core.remove_torrents"
+	GetConfigVals  = "core.get_config_values"
+	GetCoreConfig  = "core.get_config"
+	SetConfig      = "core.set_config"
+	GetListenPort  = "core.get_listen_port"
+	TestListenPort = "core.test_listen_port"
+	GetExternalIP  = "core.get_external_ip"
+	GetFreeSpace   = "core.get_free_space"
+	GetDaemonInfo  = "daemon.info"
+	GetLibtorrent  = "core.get_libtorrent_version"
+	WebConnected   = "web.connected"
+	GetWebConfig   = "web.get_config"
+	SetWebConfig   = "web.set_config"
+	RegisterEvent  = "web.register_event_listener"
+	GetEvents      = "web.get_events"
+	AddTorrents    = "web.add_torrents"
+	UpdateUI       = "web.update_ui"
+	PauseSession   = "core.pause_session"
+	ResumeSession  = "core.resume_session"
+	PauseAllTorrs  = "core.pause_all_torrents"
+	ResumeAllTorrs = "core.resume_all_torrents"
+	PauseTorrent   = "core.pause_torrent"
+	PauseTorrents  = "core.pause_torrents"
+	ResumeTorrent  = "core.resume_torrent"
+	ResumeTorrents = "core.resume_torrents"
+	ForceRecheck   = "core.force_recheck"
+	SetTorrentOpts = "core.set_torrent_options"
+	SetFirstLast   = "core.set_torrent_prioritize_first_last" // 1.x only.
+	SetMaxSlots    = "core.set_torrent_max_upload_slots"      // 1.x only.
+	SetMaxConns    = "core.set_torrent_max_connections"       // 1.x only.
+	SetMaxDown     = "core.set_torrent_max_download_speed"    // 1.x only.
+	SetMaxUp       = "core.set_torrent_max_upload_speed"      // 1.x only.
+	SetStopRatio   = "core.set_torrent_stop_ratio"            // 1.x only.
+	SetStopAtRatio = "core.set_torrent_stop_at_ratio"         // 1.x only.
+	SetRemoveRatio = "core.set_torrent_remove_at_ratio"       // 1.x only.
+	SetAutoManaged = "core.set_torrent_auto_managed"          // 1.x only.
+	SetMoveDone    = "core.set_torrent_move_completed"        // 1.x only.
+	SetMovePath    = "core.set_torrent_move_completed_path"   // 1.x only.
+	RenameFolder   = "core.rename_folder"
+	RenameFiles    = "core.rename_files"
+	SetTrackers    = "core.set_torrent_trackers"
+	SetFilePrios   = "core.set_torrent_file_priorities"
+	QueueTop       = "core.queue_top"
+	QueueUp        = "core.queue_up"
+	QueueDown      = "core.queue_down"
+	QueueBottom    = "core.queue_bottom"
+	GetStatsTotal  = "stats.get_totals"
+	GetStatsSess   = "stats.get_session_totals"
+	GetStatsHist   = "stats.get_stats"
+	GetWatchDirs   = "autoadd.get_watchdirs"
+	SetWatchDir    = "autoadd.set_options"
+	AddWatchDir    = "autoadd.add"
+	DelWatchDir    = "autoadd.remove"
+	EnableWatchDir = "autoadd.enable_watchdir"
+	StopWatchDir   = "autoadd.disable_watchdir"
+	GetScheduler   = "scheduler.get_config"
+	SetScheduler   = "scheduler.set_config"
+	GetBlocklist   = "blocklist.get_config"
+	SetBlocklist   = "blocklist.set_config"
+	CheckBlocklist = "blocklist.check_import"
+	BlocklistStat  = "blocklist.get_status"
+	GetCommands    = "execute.get_commands"
+	AddCommand     = "execute.add_command"
+	DelCommand     = "execute.remove_command"
+	SaveCommand    = "execute.save_command"
 )
 
 // Config is the data needed to poll Deluge.
+// URL normally gets /json appended; set RawURL to use URL as the JSON endpoint exactly as provided.
+//
+// Set AllowEmptyPassword if the web UI password is disabled,
+// otherwise New() returns ErrNoPassword when Password is empty.
+// New() skips auth.login when the cookie jar already has a valid session; set
+// AlwaysLogin to log in every time, like older versions of this library did.
+// Methods that apply labels return ErrUnknownLabel for missing labels, unless AutoCreateLabels is set.
+//
+// MaxResponseBytes limits the size of a response; larger responses return ErrResponseTooLarge.
+// It defaults to 0, unlimited; then only Timeout and the context stop a server that sends an
+// endless body. A full status is several kilobytes per torrent, so a limit around 100MB
+// protects against a broken server without breaking most large clients.
+// Provide a Jar to persist or customize cookies; the default is an in-memory publicsuffix jar.
+//
+// MaxRetries retries failed HTTP requests, waiting a little longer before each attempt.
+// By default transport errors and 5xx responses are retried. Provide a RetryPredicate
+// to decide what is retryable instead; MaxRetries and context cancelation still apply.
+//
+// Set MinVersion, like "2.0", to make New() return ErrVersionTooOld for older daemons.
+//
+// KeepAlive makes long polls, like WaitForState, check the web session this often and
+// log in again if it expired. The default, 0, never checks.
 type Config struct {
-	URL      string       `json:"url" toml:"url" xml:"url" yaml:"url"`
-	Password string       `json:"password" toml:"password" xml:"password" yaml:"password"`
-	HTTPPass string       `json:"http_pass" toml:"http_pass" xml:"http_pass" yaml:"http_pass"`
-	HTTPUser string       `json:"http_user" toml:"http_user" xml:"http_user" yaml:"http_user"`
-	Version  string       `json:"version" toml:"version" xml:"version" yaml:"version"`
-	Client   *http.Client `json:"-" toml:"-" xml:"-" yaml:"-"`
+	URL                string         `json:"url" toml:"url" xml:"url" yaml:"url"`
+	Password           string         `json:"password" toml:"password" xml:"password" yaml:"password"`
+	HTTPPass           string         `json:"http_pass" toml:"http_pass" xml:"http_pass" yaml:"http_pass"`
+	HTTPUser           string         `json:"http_user" toml:"http_user" xml:"http_user" yaml:"http_user"`
+	Version            string         `json:"version" toml:"version" xml:"version" yaml:"version"`
+	MinVersion         string         `json:"min_version" toml:"min_version" xml:"min_version" yaml:"min_version"`
+	AllowEmptyPassword bool           `json:"allow_empty_password" toml:"allow_empty_password" xml:"allow_empty_password" yaml:"allow_empty_password"`
+	AlwaysLogin        bool           `json:"always_login" toml:"always_login" xml:"always_login" yaml:"always_login"`
+	AutoCreateLabels   bool           `json:"auto_create_labels" toml:"auto_create_labels" xml:"auto_create_labels" yaml:"auto_create_labels"`
+	MaxResponseBytes   int64          `json:"max_response_bytes" toml:"max_response_bytes" xml:"max_response_bytes" yaml:"max_response_bytes"`
+	RawURL             bool           `json:"raw_url" toml:"raw_url" xml:"raw_url" yaml:"raw_url"`
+	MaxRetries         int            `json:"max_retries" toml:"max_retries" xml:"max_retries" yaml:"max_retries"`
+	KeepAlive          time.Duration  `json:"keep_alive" toml:"keep_alive" xml:"keep_alive" yaml:"keep_alive"`
+	RetryPredicate     RetryFunc      `json:"-" toml:"-" xml:"-" yaml:"-"`
+	Client             *http.Client   `json:"-" toml:"-" xml:"-" yaml:"-"`
+	Jar                http.CookieJar `json:"-" toml:"-" xml:"-" yaml:"-"`
 }
 
+// RetryFunc decides if a request should be retried, based on its response or error.
+type RetryFunc func(resp *http.Response, err error) bool
+
 // Response from Deluge.
 type Response struct {
 	ID     int64           `json:"id"`
@@ -39,10 +154,13 @@ type Response struct {
 }
 
 // Backend holds a WebUI's backend server data.
+// Status and Version are only filled by Discover().
 type Backend struct {
-	ID   string
-	Addr string
-	Prot string
+	ID      string
+	Addr    string
+	Prot    string
+	Status  string
+	Version string
 }
 
 // XferStatus2 is the Deluge 2.0 WebUI API layout for Active Transfers.
@@ -144,19 +262,19 @@ type XferStatus2 struct {
 		Size   int64  `json:"size"`
 		Offset int64  `json:"offset"`
 	} `json:"orig_files"`
-	IsSeed            bool          `json:"is_seed"`
-	Peers             []interface{} `json:"peers"`
-	Queue             int           `json:"queue"`
-	Ratio             float64       `json:"ratio"`
-	CompletedTime     float64       `json:"completed_time"`
-	LastSeenComplete  float64       `json:"last_seen_complete"`
-	Name              string        `json:"name"`
-	Pieces            interface{}   `json:"pieces"`
-	SeedMode          bool          `json:"seed_mode"`
-	SuperSeeding      bool          `json:"super_seeding"`
-	TimeSinceDownload float64       `json:"time_since_download"`
-	TimeSinceUpload   float64       `json:"time_since_upload"`
-	TimeSinceTransfer float64       `json:"time_since_transfer"`
+	IsSeed            bool        `json:"is_seed"`
+	Peers             []Peer      `json:"peers"`
+	Queue             int         `json:"queue"`
+	Ratio             float64     `json:"ratio"`
+	CompletedTime     float64     `json:"completed_time"`
+	LastSeenComplete  float64     `json:"last_seen_complete"`
+	Name              string      `json:"name"`
+	Pieces            interface{} `json:"pieces"`
+	SeedMode          bool        `json:"seed_mode"`
+	SuperSeeding      bool        `json:"super_seeding"`
+	TimeSinceDownload float64     `json:"time_since_download"`
+	TimeSinceUpload   float64     `json:"time_since_upload"`
+	TimeSinceTransfer float64     `json:"time_since_transfer"`
 }
 
 // XferStatus is the Deluge 1.0 WebUI API layout for Active Transfers.
@@ -239,6 +357,8 @@ type XferStatus struct {
 	Eta                  json.Number `json:"eta"`
 	StopRatio            float64     `json:"stop_ratio"`
 	IsFinished           bool        `json:"is_finished"`
+	// Extra holds raw values for status keys added with RegisterStatusKey.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // XferStatusCompat is a compatible struct for Deluge 1 and 2 API data.
@@ -316,20 +436,20 @@ type XferStatusCompat struct {
 		Size   int64  `json:"size"`
 		Offset int64  `json:"offset"`
 	} `json:"orig_files"`
-	IsSeed            bool          `json:"is_seed"`
-	Peers             []interface{} `json:"peers"`
-	Queue             int64         `json:"queue"`
-	Ratio             float64       `json:"ratio"`
-	CompletedTime     float64       `json:"completed_time"`
-	LastSeenComplete  float64       `json:"last_seen_complete"`
-	Name              string        `json:"name"`
-	Pieces            interface{}   `json:"pieces"`
-	SeedMode          bool          `json:"seed_mode"`
-	SuperSeeding      bool          `json:"super_seeding"`
-	TimeSinceDownload float64       `json:"time_since_download"`
-	TimeSinceUpload   float64       `json:"time_since_upload"`
-	TimeSinceTransfer float64       `json:"time_since_transfer"`
-	Label             string        `json:"label"`
+	IsSeed            bool        `json:"is_seed"`
+	Peers             []Peer      `json:"peers"`
+	Queue             int64       `json:"queue"`
+	Ratio             float64     `json:"ratio"`
+	CompletedTime     float64     `json:"completed_time"`
+	LastSeenComplete  float64     `json:"last_seen_complete"`
+	Name              string      `json:"name"`
+	Pieces            interface{} `json:"pieces"`
+	SeedMode          bool        `json:"seed_mode"`
+	SuperSeeding      bool        `json:"super_seeding"`
+	TimeSinceDownload float64     `json:"time_since_download"`
+	TimeSinceUpload   float64     `json:"time_since_upload"`
+	TimeSinceTransfer float64     `json:"time_since_transfer"`
+	Label             string      `json:"label"`
 	Trackers          []struct {
 		NextAnnounce     interface{}   `json:"next_announce"`
 		MinAnnounce      interface{}   `json:"min_announce"`
@@ -354,18 +474,25 @@ type XferStatusCompat struct {
 			Category string `json:"category"`
 		} `json:"last_error"`
 	} `json:"trackers"`
+	// Extra holds raw values for status keys added with RegisterStatusKey.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Bool provides a container and unmarshalling for fields that may be
 // boolean or numbers or strings in the WebUI API.
 type Bool bool
 
-// UnmarshalJSON parses fields that may be numbers or booleans.
+// UnmarshalJSON parses fields that may be numbers or booleans. Any non-zero number is true;
+// Deluge sends some flags, like a peer's seed flag, as a bit mask.
 // https://stackoverflow.com/questions/30856454/how-to-unmarshall-both-0-and-false-as-bool-from-json/56832346#56832346
 func (bit *Bool) UnmarshalJSON(b []byte) error {
 	txt := strings.Trim(string(b), `"`)
-	*bit = Bool(strings.EqualFold(txt, "1") ||
-		strings.EqualFold(txt, "true") ||
+	if num, err := json.Number(txt).Float64(); err == nil {
+		*bit = Bool(num != 0)
+		return nil
+	}
+
+	*bit = Bool(strings.EqualFold(txt, "true") ||
 		strings.EqualFold(txt, "yes") ||
 		strings.EqualFold(txt, "active"))
 
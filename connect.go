@@ -0,0 +1,92 @@
+package deluge
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+)
+
+// Connection stages reported by ConnectError.
+const (
+	StageConfig     = "config"
+	StageDNS        = "dns"
+	StageTLS        = "tls"
+	StageHTTPAuth   = "http auth"
+	StageDelugeAuth = "deluge auth"
+	StageConnect    = "connectivity"
+)
+
+// ConnectError is returned by NewWithPing, and says which stage of connecting failed.
+type ConnectError struct {
+	Stage string
+	Err   error
+}
+
+// Error satisfies the error interface.
+func (e *ConnectError) Error() string {
+	return "connecting to deluge: " + e.Stage + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// NewWithPing creates a client, logs in and pings the web UI, so a bad config fails right away.
+// Any failure is returned as a *ConnectError with the Stage that failed.
+func NewWithPing(ctx context.Context, config *Config) (*Deluge, error) {
+	deluge, err := New(ctx, config)
+	if err == nil {
+		err = deluge.Ping(ctx)
+	}
+
+	if err != nil {
+		return deluge, &ConnectError{Stage: connectStage(err), Err: err}
+	}
+
+	return deluge, nil
+}
+
+// Ping makes a cheap request to the web UI, and returns ErrNotConnected
+// if the web UI is not connected to a backend daemon.
+func (d *Deluge) Ping(ctx context.Context) error {
+	connected, err := d.WebConnected(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !connected {
+		return ErrNotConnected
+	}
+
+	return nil
+}
+
+// connectStage figures out which stage of connecting produced an error.
+func connectStage(err error) string {
+	var (
+		dnsErr       *net.DNSError
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.Is(err, ErrNoPassword):
+		return StageConfig
+	case errors.As(err, &dnsErr):
+		return StageDNS
+	case errors.As(err, &recordErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return StageTLS
+	case errors.Is(err, ErrHTTPAuthFailed):
+		return StageHTTPAuth
+	case errors.Is(err, ErrAuthFailed):
+		return StageDelugeAuth
+	default:
+		return StageConnect
+	}
+}
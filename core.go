@@ -0,0 +1,278 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// GetConfigValues returns the raw values for the requested Deluge daemon config keys.
+// Keys the daemon does not know are missing from the returned map.
+func (d *Deluge) GetConfigValues(ctx context.Context, keys []string) (map[string]json.RawMessage, error) {
+	response, err := d.Get(ctx, GetConfigVals, []interface{}{keys})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetConfigVals): %w", err)
+	}
+
+	values := make(map[string]json.RawMessage)
+	if err := decodeResult(response.Result, &values); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(values): %w", err)
+	}
+
+	return values, nil
+}
+
+// GetConfigValue returns the raw value for a single Deluge daemon config key, like download_location.
+// Returns ErrConfigKeyNotFound if Deluge does not return the key.
+func (d *Deluge) GetConfigValue(ctx context.Context, key string) (json.RawMessage, error) {
+	values, err := d.GetConfigValues(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrConfigKeyNotFound, key)
+	}
+
+	return value, nil
+}
+
+// SetConfig sets one or more Deluge daemon config values.
+// Some Deluge versions silently ignore unknown keys. Set verify to read the keys back
+// after setting them; this returns ErrConfigNotApplied listing any key that did not
+// take the new value. Verifying costs a second request.
+func (d *Deluge) SetConfig(ctx context.Context, values map[string]interface{}, verify bool) error {
+	if _, err := d.Get(ctx, SetConfig, []interface{}{values}); err != nil {
+		return fmt.Errorf("get(SetConfig): %w", err)
+	}
+
+	if !verify {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	current, err := d.GetConfigValues(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	failed := []string{}
+
+	for _, key := range keys {
+		if !sameJSON(values[key], current[key]) {
+			failed = append(failed, key)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", ErrConfigNotApplied, strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// sameJSON compares a value with raw JSON by decoding both into generic types.
+func sameJSON(value interface{}, raw json.RawMessage) bool {
+	if raw == nil {
+		return false
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	var want, have interface{}
+
+	if json.Unmarshal(data, &want) != nil || json.Unmarshal(raw, &have) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(want, have)
+}
+
+// SetDownloadLocation sets the default download location for new torrents.
+// The path must be absolute on the Deluge server; Windows paths are allowed.
+// If the daemon rejects the path, its error is returned as-is.
+func (d *Deluge) SetDownloadLocation(ctx context.Context, path string) error {
+	if !isAbsPath(path) {
+		return fmt.Errorf("%w: %s", ErrInvalidPath, path)
+	}
+
+	return d.SetConfig(ctx, map[string]interface{}{"download_location": path}, false)
+}
+
+// isAbsPath checks for an absolute path on a server that may not share our OS.
+func isAbsPath(path string) bool {
+	const minWinPath = 3 // C:\
+
+	switch {
+	case strings.HasPrefix(path, "/"), strings.HasPrefix(path, `\\`):
+		return true
+	case len(path) >= minWinPath && path[1] == ':' && (path[2] == '\\' || path[2] == '/'):
+		return true
+	default:
+		return false
+	}
+}
+
+// GetListenPort returns the port the Deluge daemon listens on for incoming peer connections.
+func (d *Deluge) GetListenPort(ctx context.Context) (int, error) {
+	response, err := d.Get(ctx, GetListenPort, []string{})
+	if err != nil {
+		return 0, fmt.Errorf("get(GetListenPort): %w", err)
+	}
+
+	var port int
+	if err := decodeResult(response.Result, &port); err != nil {
+		return 0, fmt.Errorf("json.Unmarshal(port): %w", err)
+	}
+
+	return port, nil
+}
+
+// TestListenPortOpen returns true if the daemon's listen port is reachable from the internet.
+// The daemon asks an external service (run by the Deluge project) to connect to it,
+// so this may be slow; consider passing a context with a deadline.
+func (d *Deluge) TestListenPortOpen(ctx context.Context) (bool, error) {
+	response, err := d.Get(ctx, TestListenPort, []string{})
+	if err != nil {
+		return false, fmt.Errorf("get(TestListenPort): %w", err)
+	}
+
+	var open bool
+	if err := decodeResult(response.Result, &open); err != nil {
+		return false, fmt.Errorf("json.Unmarshal(open): %w", err)
+	}
+
+	return open, nil
+}
+
+// GetExternalIP returns the daemon's external IP address, as seen by peers. This requires Deluge 2.x.
+// The address is empty until libtorrent learns it, shortly after the daemon starts.
+func (d *Deluge) GetExternalIP(ctx context.Context) (string, error) {
+	response, err := d.Get(ctx, GetExternalIP, []string{})
+	if err != nil {
+		return "", fmt.Errorf("get(GetExternalIP): %w", err)
+	}
+
+	var address string
+	if err := decodeResult(response.Result, &address); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(address): %w", err)
+	}
+
+	return address, nil
+}
+
+// GetListenInterface returns the network interface (IP address) the daemon listens on for peers.
+// An empty string means all interfaces.
+func (d *Deluge) GetListenInterface(ctx context.Context) (string, error) {
+	value, err := d.GetConfigValue(ctx, "listen_interface")
+	if err != nil {
+		return "", err
+	}
+
+	var iface string
+	if err := json.Unmarshal(value, &iface); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(listen_interface): %w", err)
+	}
+
+	return iface, nil
+}
+
+// SetListenInterface binds the daemon's peer connections to a network interface, like a VPN tunnel.
+// If the daemon rejects the interface, its error is returned as-is.
+func (d *Deluge) SetListenInterface(ctx context.Context, iface string) error {
+	if iface == "" {
+		return fmt.Errorf("%w: listen interface must not be empty", ErrInvalidValue)
+	}
+
+	return d.SetConfig(ctx, map[string]interface{}{"listen_interface": iface}, false)
+}
+
+// CoreConfig holds the common Deluge daemon settings, from GetCoreConfig.
+// Speeds are in KiB/s, and -1 means unlimited for speeds, connections and slots.
+// Encryption policies are 0 forced, 1 enabled, 2 disabled; the level is 0 handshake, 1 full, 2 either.
+type CoreConfig struct {
+	DaemonPort                int      `json:"daemon_port"`
+	AllowRemote               bool     `json:"allow_remote"`
+	ListenPorts               []int    `json:"listen_ports"`
+	ListenInterface           string   `json:"listen_interface"`
+	RandomPort                bool     `json:"random_port"`
+	OutgoingPorts             []int    `json:"outgoing_ports"`
+	RandomOutgoingPorts       bool     `json:"random_outgoing_ports"`
+	DHT                       bool     `json:"dht"`
+	UPnP                      bool     `json:"upnp"`
+	NATPMP                    bool     `json:"natpmp"`
+	UTPEX                     bool     `json:"utpex"`
+	LSD                       bool     `json:"lsd"`
+	EncInPolicy               int      `json:"enc_in_policy"`
+	EncOutPolicy              int      `json:"enc_out_policy"`
+	EncLevel                  int      `json:"enc_level"`
+	MaxConnectionsGlobal      int      `json:"max_connections_global"`
+	MaxUploadSlotsGlobal      int      `json:"max_upload_slots_global"`
+	MaxDownloadSpeed          float64  `json:"max_download_speed"`
+	MaxUploadSpeed            float64  `json:"max_upload_speed"`
+	MaxHalfOpenConnections    int      `json:"max_half_open_connections"`
+	MaxConnectionsPerSecond   int      `json:"max_connections_per_second"`
+	MaxConnectionsPerTorrent  int      `json:"max_connections_per_torrent"`
+	MaxUploadSlotsPerTorrent  int      `json:"max_upload_slots_per_torrent"`
+	MaxDownloadSpeedTorrent   float64  `json:"max_download_speed_per_torrent"`
+	MaxUploadSpeedTorrent     float64  `json:"max_upload_speed_per_torrent"`
+	MaxActiveLimit            int      `json:"max_active_limit"`
+	MaxActiveDownloading      int      `json:"max_active_downloading"`
+	MaxActiveSeeding          int      `json:"max_active_seeding"`
+	DontCountSlowTorrents     bool     `json:"dont_count_slow_torrents"`
+	QueueNewToTop             bool     `json:"queue_new_to_top"`
+	StopSeedAtRatio           bool     `json:"stop_seed_at_ratio"`
+	StopSeedRatio             float64  `json:"stop_seed_ratio"`
+	RemoveSeedAtRatio         bool     `json:"remove_seed_at_ratio"`
+	ShareRatioLimit           float64  `json:"share_ratio_limit"`
+	SeedTimeRatioLimit        float64  `json:"seed_time_ratio_limit"`
+	SeedTimeLimit             float64  `json:"seed_time_limit"`
+	DownloadLocation          string   `json:"download_location"`
+	MoveCompleted             bool     `json:"move_completed"`
+	MoveCompletedPath         string   `json:"move_completed_path"`
+	CopyTorrentFile           bool     `json:"copy_torrent_file"`
+	TorrentFilesLocation      string   `json:"torrentfiles_location"`
+	DelCopyTorrentFile        bool     `json:"del_copy_torrent_file"`
+	AddPaused                 bool     `json:"add_paused"`
+	PrioritizeFirstLastPieces bool     `json:"prioritize_first_last_pieces"`
+	SequentialDownload        bool     `json:"sequential_download"`
+	PreAllocateStorage        bool     `json:"pre_allocate_storage"`
+	AutoManaged               bool     `json:"auto_managed"`
+	CacheSize                 int      `json:"cache_size"` // 16KiB blocks.
+	EnabledPlugins            []string `json:"enabled_plugins"`
+}
+
+// GetCoreConfig returns the common Deluge daemon settings.
+// Use GetConfigValues for keys that are not in CoreConfig.
+func (d *Deluge) GetCoreConfig(ctx context.Context) (*CoreConfig, error) {
+	response, err := d.Get(ctx, GetCoreConfig, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetCoreConfig): %w", err)
+	}
+
+	var config CoreConfig
+	if err := decodeResult(response.Result, &config); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(config): %w", err)
+	}
+
+	return &config, nil
+}
+
+// SetCoreConfig changes Deluge daemon settings, keyed by the json names in CoreConfig.
+// It is SetConfig without verification; use SetConfig to read the values back.
+func (d *Deluge) SetCoreConfig(ctx context.Context, changes map[string]interface{}) error {
+	return d.SetConfig(ctx, changes, false)
+}
@@ -1,7 +1,6 @@
 package deluge
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -9,17 +8,55 @@ import (
 	"io"
 	"net/http"
 	"net/http/cookiejar"
-	"strconv"
+	"net/url"
+	"reflect"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/net/publicsuffix"
 )
 
 // Custom errors.
 var (
-	ErrInvalidVersion = fmt.Errorf("invalid data returned while checking version")
-	ErrDelugeError    = fmt.Errorf("deluge error")
-	ErrAuthFailed     = fmt.Errorf("authentication failed")
+	ErrInvalidVersion     = fmt.Errorf("invalid data returned while checking version")
+	ErrDelugeError        = fmt.Errorf("deluge error")
+	ErrAuthFailed         = fmt.Errorf("authentication failed")
+	ErrHTTPAuthFailed     = fmt.Errorf("%w: http", ErrAuthFailed)
+	ErrNotConnected       = fmt.Errorf("web UI is not connected to a daemon")
+	ErrNoPassword         = fmt.Errorf("no password provided")
+	ErrPluginNotInstalled = fmt.Errorf("plugin not installed")
+	ErrUnknownLabel       = fmt.Errorf("unknown label")
+	ErrNameMismatch       = fmt.Errorf("torrent name does not match")
+	ErrConfigKeyNotFound  = fmt.Errorf("config key not found")
+	ErrMethodNotFound     = fmt.Errorf("%w: unknown method", ErrDelugeError)
+	ErrInvalidPath        = fmt.Errorf("path must be absolute")
+	ErrUnsupportedVersion = fmt.Errorf("not supported by this Deluge version")
+	ErrConfigNotApplied   = fmt.Errorf("config values not applied")
+	ErrPermissionDenied   = fmt.Errorf("%w: permission denied", ErrDelugeError)
+	ErrInvalidSchedule    = fmt.Errorf("invalid schedule")
+	ErrAlreadyAdded       = fmt.Errorf("torrent already added")
+	ErrCannotRename       = fmt.Errorf("torrent cannot be renamed")
+	ErrResponseTooLarge   = fmt.Errorf("response too large")
+	ErrNotQueued          = fmt.Errorf("torrent is not queued")
+	ErrInvalidValue       = fmt.Errorf("invalid value")
+	ErrNoTorrentFile      = fmt.Errorf("torrent file not available")
+	ErrVersionTooOld      = fmt.Errorf("deluge version is too old")
+	ErrUnknownEvent       = fmt.Errorf("unknown event")
+	ErrTorrentNotFound    = fmt.Errorf("torrent not found")
+)
+
+// maxDiscardBytes is the most we read from a response body we do not care about.
+// Protects against a misbehaving server that streams a body forever.
+const maxDiscardBytes = 4 * 1024 * 1024
+
+// Error codes returned by the web UI.
+const (
+	// errorCodeNotAuthenticated is returned when the session cookie is missing or expired.
+	errorCodeNotAuthenticated = 1
+	// errorCodeUnknownMethod is returned for methods the web UI does not know. This happens
+	// when a method belongs to a disabled plugin, or a different Deluge version.
+	errorCodeUnknownMethod = 2
 )
 
 // Deluge is what you get for providing a password.
@@ -28,7 +65,14 @@ type Deluge struct {
 	password string
 	url      string
 	auth     string
-	id       int
+	id       *int64 // shared with copies, so request IDs always increase.
+	timeout  time.Duration
+	maxBytes int64
+	retries  int
+	retryIf  RetryFunc
+	addLabel bool
+	alive    time.Duration
+	log      Logger
 	client   *http.Client
 	Version  string             // Currently unused, for display purposes only.
 	Backends map[string]Backend // Currently unused, for display purposes only.
@@ -47,12 +91,20 @@ func New(ctx context.Context, config *Config) (*Deluge, error) {
 
 func newConfig(ctx context.Context, config *Config, login bool) (*Deluge, error) {
 	// The cookie jar is used to auth Deluge.
-	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
-	if err != nil {
-		return nil, fmt.Errorf("cookiejar.New(publicsuffix): %w", err)
+	jar := config.Jar
+	if jar == nil {
+		var err error
+
+		jar, err = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		if err != nil {
+			return nil, fmt.Errorf("cookiejar.New(publicsuffix): %w", err)
+		}
 	}
 
-	delugeURL := strings.TrimSuffix(strings.TrimSuffix(config.URL, "/json"), "/") + "/json"
+	delugeURL := config.URL
+	if !config.RawURL {
+		delugeURL = strings.TrimSuffix(strings.TrimSuffix(config.URL, "/json"), "/") + "/json"
+	}
 
 	// This app allows http auth, in addition to deluge web password.
 	auth := config.HTTPUser + ":" + config.HTTPPass
@@ -71,6 +123,12 @@ func newConfig(ctx context.Context, config *Config, login bool) (*Deluge, error)
 
 	deluge := &Deluge{
 		auth:     auth,
+		id:       new(int64),
+		maxBytes: config.MaxResponseBytes,
+		retries:  config.MaxRetries,
+		retryIf:  config.RetryPredicate,
+		addLabel: config.AutoCreateLabels,
+		alive:    config.KeepAlive,
 		Backends: make(map[string]Backend),
 		password: config.Password,
 		url:      delugeURL,
@@ -81,17 +139,30 @@ func newConfig(ctx context.Context, config *Config, login bool) (*Deluge, error)
 		return deluge, nil
 	}
 
-	if err := deluge.LoginContext(ctx); err != nil {
-		return deluge, err
+	return deluge, deluge.start(ctx, config)
+}
+
+// start logs in, detects the server version, and checks it against config.MinVersion.
+func (d *Deluge) start(ctx context.Context, config *Config) error {
+	if config.Password == "" && !config.AllowEmptyPassword {
+		return ErrNoPassword
+	}
+
+	if err := d.login(ctx, config.AlwaysLogin); err != nil {
+		return err
 	}
 
-	if deluge.Version = config.Version; deluge.Version == "" {
-		if err = deluge.setVersion(ctx); err != nil {
-			return deluge, err
+	if d.Version = config.Version; d.Version == "" {
+		if err := d.setVersion(ctx); err != nil {
+			return err
 		}
 	}
 
-	return deluge, nil
+	if config.MinVersion != "" && !VersionAtLeast(d.Version, config.MinVersion) {
+		return fmt.Errorf("%w: have %s, need %s", ErrVersionTooOld, d.Version, config.MinVersion)
+	}
+
+	return nil
 }
 
 // Login sets the cookie jar with authentication information.
@@ -101,97 +172,159 @@ func (d *Deluge) Login() error {
 
 // LoginContext sets the cookie jar with authentication information.
 func (d *Deluge) LoginContext(ctx context.Context) error {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
 	// This line is how you send auth creds.
 	req, err := d.DelReq(ctx, AuthLogin, []string{d.password})
 	if err != nil {
 		return fmt.Errorf("DelReq(AuthLogin, json): %w", err)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := d.do(req)
 	if err != nil {
 		return fmt.Errorf("d.Do(req): %w", err)
 	}
 	defer resp.Body.Close()
 
-	_, _ = io.Copy(io.Discard, resp.Body) // must read body to avoid memory leak.
+	// must read body to avoid memory leak, but never read forever from a broken server.
+	body := io.LimitReader(resp.Body, maxDiscardBytes)
+	defer func() { _, _ = io.Copy(io.Discard, body) }()
 
-	if resp.StatusCode != http.StatusOK {
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%w: %v[%v] (status: %v/%v)",
+			ErrHTTPAuthFailed, req.URL.String(), AuthLogin, resp.StatusCode, resp.Status)
+	case resp.StatusCode != http.StatusOK:
 		return fmt.Errorf("%w: %v[%v] (status: %v/%v)",
 			ErrAuthFailed, req.URL.String(), AuthLogin, resp.StatusCode, resp.Status)
 	}
 
+	// Deluge returns a false result for a bad password.
+	var response Response
+	if json.NewDecoder(body).Decode(&response) == nil && string(response.Result) == "false" {
+		return fmt.Errorf("%w: %v[%v] (incorrect password)", ErrAuthFailed, req.URL.String(), AuthLogin)
+	}
+
 	return nil
 }
 
+// WithTimeout returns a copy of the Deluge client that applies a timeout to each request
+// when the provided context has no deadline. An explicit deadline on the context always wins.
+// The original client is not changed; the copy shares its http client and cookies.
+func (d *Deluge) WithTimeout(timeout time.Duration) *Deluge {
+	clone := *d
+	clone.timeout = timeout
+
+	return &clone
+}
+
+// withTimeout derives a context with the client's default timeout, if it has one and ctx has no deadline.
+func (d *Deluge) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || d.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, d.timeout)
+}
+
+// login only calls auth.login if the cookie jar lacks a valid session, unless always is true.
+// The session is not checked when the jar has no cookies for the web UI; it cannot be valid.
+func (d *Deluge) login(ctx context.Context, always bool) error {
+	if !always && d.hasCookies() {
+		if valid, err := d.CheckSession(ctx); err == nil && valid {
+			return nil
+		}
+	}
+
+	return d.LoginContext(ctx)
+}
+
+// hasCookies returns true if the cookie jar has any cookies for the web UI URL.
+func (d *Deluge) hasCookies() bool {
+	if d.client == nil || d.client.Jar == nil {
+		return false
+	}
+
+	webURL, err := url.Parse(d.url)
+	if err != nil {
+		return false
+	}
+
+	return len(d.client.Jar.Cookies(webURL)) > 0
+}
+
+// CheckSession returns true if the current cookie is a valid, authenticated web UI session.
+func (d *Deluge) CheckSession(ctx context.Context) (bool, error) {
+	response, err := d.Get(ctx, CheckSession, []string{})
+	if err != nil {
+		return false, fmt.Errorf("get(CheckSession): %w", err)
+	}
+
+	var valid bool
+	if err := json.Unmarshal(response.Result, &valid); err != nil {
+		return false, fmt.Errorf("json.Unmarshal(valid): %w", err)
+	}
+
+	return valid, nil
+}
+
+// isV1 returns true if the Deluge server is version 1.x. An unknown version is treated as 2.x.
+func (d *Deluge) isV1() bool {
+	return strings.HasPrefix(d.Version, "1.")
+}
+
 // setVersion digs into the first server in the web UI to find the version.
 func (d *Deluge) setVersion(ctx context.Context) error {
-	response, err := d.Get(ctx, GeHosts, []string{})
+	backends, err := d.GetHosts(ctx)
 	if err != nil {
 		return err
 	}
 
-	// This method returns a "mixed list" which requires an interface.
-	// Deluge devs apparently hate Go. :(
-	servers := make([][]interface{}, 0)
-	if err := json.Unmarshal(response.Result, &servers); err != nil {
-		return fmt.Errorf("json.Unmarshal(rawResult1): %w", err)
-	}
-
 	serverID := ""
 
 	// Store each server info (so consumers can access them easily).
-	for _, server := range servers {
-		serverID, _ = server[0].(string)
-		backend := Backend{ID: serverID}
-		backend.Addr, _ = server[1].(string)
-		val, _ := server[2].(float64)
-		backend.Addr += ":" + strconv.FormatFloat(val, 'f', 0, 64) //nolint:gomnd,nolintlint
-		backend.Prot, _ = server[3].(string)
+	for _, backend := range backends {
+		serverID = backend.ID
 		d.Backends[serverID] = backend
 	}
 
 	// Store the last server's version as "the version"
-	response, err = d.Get(ctx, HostStatus, []string{serverID})
-	if err != nil {
-		return err
-	}
+	_, d.Version, err = d.GetHostStatus(ctx, serverID)
 
-	server := make([]interface{}, 0)
-	if err = json.Unmarshal(response.Result, &server); err != nil {
-		return fmt.Errorf("json.Unmarshal(rawResult2): %w", err)
-	}
-
-	const payloadSegments = 3
+	return err
+}
 
-	if len(server) < payloadSegments {
-		return ErrInvalidVersion
-	}
+// requestID numbers requests from a Deluge that was not built by New or NewNoAuth.
+//
+//nolint:gochecknoglobals
+var requestID int64
 
-	// Version comes last in the mixed list.
-	var ok bool
-	if d.Version, ok = server[len(server)-1].(string); !ok {
-		return ErrInvalidVersion
+// nextID returns the next JSON-RPC request id. A Deluge built as a struct literal has no
+// counter of its own, so it shares requestID; those ids still always increase.
+func (d Deluge) nextID() int64 {
+	if d.id == nil {
+		return atomic.AddInt64(&requestID, 1)
 	}
 
-	return nil
+	return atomic.AddInt64(d.id, 1)
 }
 
 // DelReq is a small helper function that adds headers and marshals the json.
 func (d Deluge) DelReq(ctx context.Context, method string, params interface{}) (*http.Request, error) {
-	d.id++
-
-	paramMap := map[string]interface{}{"method": method, "id": d.id, "params": params}
-
-	data, err := json.Marshal(paramMap)
+	body, err := newRequestBody(d.nextID(), method, params)
 	if err != nil {
-		return nil, fmt.Errorf("json.Marshal(params): %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, body.reader())
 	if err != nil {
 		return req, fmt.Errorf("creating request: %w", err)
 	}
 
+	req.ContentLength = body.size()
+	req.GetBody = func() (io.ReadCloser, error) { return body.reader(), nil }
+
 	if d.auth != "" {
 		// In case Deluge is also behind HTTP auth.
 		req.Header.Add("Authorization", d.auth)
@@ -208,6 +341,8 @@ func (d *Deluge) GetXfers() (map[string]*XferStatus, error) {
 	return d.GetXfersContext(context.Background())
 }
 
+// GetXfersContext gets all the Transfers from Deluge. Every status key is requested, so
+// values for keys added with RegisterStatusKey are stored in Extra when Deluge has them.
 func (d *Deluge) GetXfersContext(ctx context.Context) (map[string]*XferStatus, error) {
 	xfers := make(map[string]*XferStatus)
 
@@ -220,6 +355,17 @@ func (d *Deluge) GetXfersContext(ctx context.Context) (map[string]*XferStatus, e
 		return nil, fmt.Errorf("json.Unmarshal(xfers): %w", err)
 	}
 
+	extra, err := extraValues(response.Result, registeredKeys())
+	if err != nil {
+		return nil, err
+	}
+
+	for hash, values := range extra {
+		if xfer := xfers[hash]; xfer != nil {
+			xfer.Extra = values
+		}
+	}
+
 	return xfers, nil
 }
 
@@ -232,9 +378,29 @@ func (d *Deluge) GetXfersCompat() (map[string]*XferStatusCompat, error) {
 }
 
 func (d *Deluge) GetXfersCompatContext(ctx context.Context) (map[string]*XferStatusCompat, error) {
+	return d.GetXfersCompatFields(ctx, nil, nil)
+}
+
+// GetXfersCompatFields gets the Transfers matching filter from Deluge 1.x or 2.x, populating only
+// the requested status keys. Requesting fewer keys, or fewer transfers, saves a lot of bandwidth
+// on large clients. A nil filter and nil keys return the same data as GetXfersCompat.
+// Filter keys include state, label, owner, tracker_host and id (a list of hashes).
+// Keys added with RegisterStatusKey are always requested, and stored in Extra.
+func (d *Deluge) GetXfersCompatFields(ctx context.Context,
+	filter map[string]interface{}, keys []string,
+) (map[string]*XferStatusCompat, error) {
 	xfers := make(map[string]*XferStatusCompat)
 
-	response, err := d.Get(ctx, GetAllTorrents, []string{"", ""})
+	extra := registeredKeys()
+	if len(extra) > 0 {
+		if len(keys) == 0 {
+			keys = jsonKeys(reflect.TypeOf(XferStatusCompat{}))
+		}
+
+		keys = append(append([]string{}, keys...), extra...)
+	}
+
+	response, err := d.Get(ctx, GetAllTorrents, statusParams(filter, keys))
 	if err != nil {
 		return nil, fmt.Errorf("get(GetAllTorrents): %w", err)
 	}
@@ -243,40 +409,162 @@ func (d *Deluge) GetXfersCompatContext(ctx context.Context) (map[string]*XferSta
 		return nil, fmt.Errorf("json.Unmarshal(xfers): %w", err)
 	}
 
+	values, err := extraValues(response.Result, extra)
+	if err != nil {
+		return nil, err
+	}
+
+	for hash, extra := range values {
+		if xfer := xfers[hash]; xfer != nil {
+			xfer.Extra = extra
+		}
+	}
+
 	return xfers, nil
 }
 
+// extraValues returns the raw values of keys, per hash, from a get_torrents_status result.
+// Hashes without any of the keys are left out.
+func extraValues(result json.RawMessage, keys []string) (map[string]map[string]json.RawMessage, error) {
+	values := make(map[string]map[string]json.RawMessage)
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	raw := make(map[string]map[string]json.RawMessage)
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(extra): %w", err)
+	}
+
+	for hash, status := range raw {
+		for _, key := range keys {
+			if value, ok := status[key]; ok {
+				if values[hash] == nil {
+					values[hash] = make(map[string]json.RawMessage)
+				}
+
+				values[hash][key] = value
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// GetXfersCompatTagged returns the same data as GetXfersCompat, and the Deluge version that
+// produced it, so callers can choose version-specific helpers without another request.
+// The version is d.Version when it is known. Otherwise it is inferred from the data:
+// Deluge 2.x reports download_location, so "2.x" is returned if any transfer has one,
+// and "1.x" if transfers only have save_path. With no transfers the version is "".
+func (d *Deluge) GetXfersCompatTagged(ctx context.Context) (map[string]*XferStatusCompat, string, error) {
+	xfers, err := d.GetXfersCompatContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if d.Version != "" {
+		return xfers, d.Version, nil
+	}
+
+	version := ""
+
+	for _, xfer := range xfers {
+		if xfer.DownloadLocation != "" {
+			return xfers, "2.x", nil
+		}
+
+		if xfer.SavePath != "" {
+			version = "1.x"
+		}
+	}
+
+	return xfers, version, nil
+}
+
+// statusParams builds the get_torrents_status parameters. An empty filter or
+// key list is sent as an empty string, which Deluge treats as "everything".
+func statusParams(filter map[string]interface{}, keys []string) []interface{} {
+	params := []interface{}{"", ""}
+
+	if len(filter) > 0 {
+		params[0] = filter
+	}
+
+	if len(keys) > 0 {
+		params[1] = keys
+	}
+
+	return params
+}
+
+// decodeResult decodes a response result into v. A null (or missing) result leaves v
+// at its zero value instead of failing, so getters return "", false or 0 for null.
+func decodeResult(result json.RawMessage, v interface{}) error {
+	if len(result) == 0 || string(result) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(result, v) //nolint:wrapcheck
+}
+
 // Get a response from Deluge.
 func (d *Deluge) Get(ctx context.Context, method string, params interface{}) (*Response, error) {
 	return d.req(ctx, method, params, true)
 }
 
 func (d *Deluge) req(ctx context.Context, method string, params interface{}, loop bool) (*Response, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
 	req, err := d.DelReq(ctx, method, params)
 	if err != nil {
 		return nil, fmt.Errorf("d.DelReq: %w", err)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := d.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("d.Do: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Without MaxResponseBytes, only the context and Config.Timeout bound the read.
+	body := io.Reader(resp.Body)
+	limited := &io.LimitedReader{R: resp.Body, N: d.maxBytes + 1}
+
+	if d.maxBytes > 0 {
+		body = limited
+	}
+
 	var response Response
-	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	err = json.NewDecoder(body).Decode(&response)
+
+	if d.maxBytes > 0 && limited.N <= 0 {
+		return nil, fmt.Errorf("%w: %s: more than %d bytes", ErrResponseTooLarge, method, d.maxBytes)
+	} else if err != nil {
 		return nil, fmt.Errorf("json.Unmarshal(response): %w", err)
 	}
 
-	if response.Error.Code != 0 {
+	// Drain anything trailing the JSON payload so the connection may be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDiscardBytes))
+
+	if response.Error.Code == errorCodeUnknownMethod {
+		// Logging in again will not make a missing method (or plugin) appear.
+		return &response, fmt.Errorf("%w: %s: %s", ErrMethodNotFound, method, response.Error.Message)
+	}
+
+	if response.Error.Code == errorCodeNotAuthenticated && loop {
+		// Only an expired session is worth logging in again for. Other errors come from
+		// the method itself, and sending it again may repeat its side effects.
+		d.logf("deluge: %s: %s (code %d), logging in again", method, response.Error.Message, response.Error.Code)
+
 		if err := d.LoginContext(ctx); err != nil {
 			return nil, err
 		}
 
-		if loop {
-			return d.req(ctx, method, params, false)
-		}
+		return d.req(ctx, method, params, false)
+	}
 
+	if response.Error.Code != 0 {
 		return &response, fmt.Errorf("%w: %s", ErrDelugeError, response.Error.Message)
 	}
 
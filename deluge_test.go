@@ -0,0 +1,1077 @@
+package deluge_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golift.io/deluge"
+	"golift.io/deluge/delugetest"
+)
+
+func TestIDsIncreaseAcrossLogin(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := client.GetXfersCompatContext(ctx); err != nil {
+		t.Fatalf("GetXfersCompat: %v", err)
+	}
+
+	server.Expire()
+
+	if _, err := client.GetXfersCompatContext(ctx); err != nil {
+		t.Fatalf("GetXfersCompat after expiry: %v", err)
+	}
+
+	server.Lock()
+	defer server.Unlock()
+
+	logins := 0
+
+	for i, method := range server.Calls {
+		if method == deluge.AuthLogin {
+			logins++
+		}
+
+		if i > 0 && server.IDs[i] <= server.IDs[i-1] {
+			t.Errorf("call %d (%s): id %d does not follow %d", i, method, server.IDs[i], server.IDs[i-1])
+		}
+	}
+
+	if logins != 2 {
+		t.Errorf("logins: got %d, want 2 (calls: %v)", logins, server.Calls)
+	}
+}
+
+func TestDelReqWithoutNew(t *testing.T) {
+	t.Parallel()
+
+	var last int64
+
+	for i := 0; i < 3; i++ {
+		req, err := (&deluge.Deluge{}).DelReq(context.Background(), deluge.CheckSession, []string{})
+		if err != nil {
+			t.Fatalf("DelReq: %v", err)
+		}
+
+		var body struct {
+			ID int64 `json:"id"`
+		}
+
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+
+		if body.ID <= last {
+			t.Errorf("id %d does not follow %d", body.ID, last)
+		}
+
+		last = body.ID
+	}
+}
+
+// endlessBody starts a JSON-RPC response and never finishes it. It writes a chunk
+// every delay, until the client hangs up.
+func endlessBody(delay time.Duration) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		chunk := bytes.Repeat([]byte("a"), 32*1024)
+
+		resp.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(resp, `{"id": 1, "error": null, "result": "`)
+
+		for req.Context().Err() == nil {
+			if _, err := resp.Write(chunk); err != nil {
+				return
+			}
+
+			if delay > 0 {
+				resp.(http.Flusher).Flush()
+				time.Sleep(delay)
+			}
+		}
+	}
+}
+
+func TestSlowBodyDeadline(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(endlessBody(10 * time.Millisecond))
+	defer server.Close()
+
+	client, err := deluge.NewNoAuth(&deluge.Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewNoAuth: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := client.Get(ctx, deluge.CheckSession, []string{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error: got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %v after a 100ms deadline", elapsed)
+	}
+}
+
+func TestEndlessBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(endlessBody(0))
+	defer server.Close()
+
+	client, err := deluge.NewNoAuth(&deluge.Config{URL: server.URL, MaxResponseBytes: 1024 * 1024})
+	if err != nil {
+		t.Fatalf("NewNoAuth: %v", err)
+	}
+
+	if _, err := client.Get(context.Background(), deluge.CheckSession, []string{}); !errors.Is(err, deluge.ErrResponseTooLarge) {
+		t.Errorf("error: got %v, want %v", err, deluge.ErrResponseTooLarge)
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	t.Parallel()
+
+	const method = "test.large"
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	server.Handle(method, func([]json.RawMessage) (interface{}, error) {
+		return strings.Repeat("x", 4096), nil
+	})
+
+	config := server.Config()
+	config.MaxResponseBytes = 1024
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := client.Get(ctx, method, []string{}); !errors.Is(err, deluge.ErrResponseTooLarge) {
+		t.Errorf("error: got %v, want %v", err, deluge.ErrResponseTooLarge)
+	}
+}
+
+func TestAddTorrentDuplicate(t *testing.T) {
+	t.Parallel()
+
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	server.Handle(deluge.AddTorrentFile, func([]json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("AddTorrentError: Torrent already in session (%s).", hash)
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	got, err := client.AddTorrentFile(ctx, "test.torrent", []byte("d4:infod4:name4:testee"), nil)
+	if !errors.Is(err, deluge.ErrAlreadyAdded) {
+		t.Errorf("error: got %v, want %v", err, deluge.ErrAlreadyAdded)
+	}
+
+	if got != hash {
+		t.Errorf("hash: got %q, want %q", got, hash)
+	}
+
+	server.Lock()
+	defer server.Unlock()
+
+	adds, logins := 0, 0
+
+	for _, method := range server.Calls {
+		switch method {
+		case deluge.AddTorrentFile:
+			adds++
+		case deluge.AuthLogin:
+			logins++
+		}
+	}
+
+	if adds != 1 || logins != 1 {
+		t.Errorf("got %d adds and %d logins, want 1 of each (calls: %v)", adds, logins, server.Calls)
+	}
+}
+
+func TestLoginChecksSessionOnlyWithCookies(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("cookiejar.New: %v", err)
+	}
+
+	config := server.Config()
+	config.Jar = jar
+	ctx := context.Background()
+
+	for _, want := range []string{deluge.AuthLogin, deluge.CheckSession} {
+		server.Lock()
+		server.Calls = nil
+		server.Unlock()
+
+		if _, err := deluge.New(ctx, config); err != nil {
+			t.Fatalf("New: %v", err)
+		}
+
+		server.Lock()
+		calls := server.Calls
+		server.Unlock()
+
+		if calls[0] != want {
+			t.Errorf("first call: got %s, want %s (calls: %v)", calls[0], want, calls)
+		}
+	}
+}
+
+func TestMoveCompletedFixtures(t *testing.T) {
+	t.Parallel()
+
+	const hash = "1111111111111111111111111111111111111111"
+
+	for _, version := range []string{delugetest.Version1, delugetest.Version2} {
+		server := delugetest.NewServer(version)
+		defer server.Close()
+
+		status := delugetest.Torrent(version, hash, "test", "Seeding")
+		if version == delugetest.Version1 {
+			status["move_on_completed"] = true
+		} else {
+			status["move_completed"] = true
+		}
+
+		server.AddTorrent(hash, status)
+
+		ctx := context.Background()
+
+		client, err := deluge.New(ctx, server.Config())
+		if err != nil {
+			t.Fatalf("%s: New: %v", version, err)
+		}
+
+		xfers, err := client.GetXfersCompatContext(ctx)
+		if err != nil {
+			t.Fatalf("%s: GetXfersCompat: %v", version, err)
+		}
+
+		if !xfers[hash].MoveCompletedEnabled() {
+			t.Errorf("%s: MoveCompletedEnabled: got false, want true", version)
+		}
+
+		if got := xfers[hash].MoveCompletedLocation(); got != "/completed" {
+			t.Errorf("%s: MoveCompletedLocation: got %q, want /completed", version, got)
+		}
+	}
+}
+
+func TestGetSessionState(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hash1 = "1111111111111111111111111111111111111111"
+		hash2 = "2222222222222222222222222222222222222222"
+	)
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	server.AddTorrent(hash1, delugetest.Torrent(delugetest.Version2, hash1, "one", "Seeding"))
+	server.AddTorrent(hash2, delugetest.Torrent(delugetest.Version2, hash2, "two", "Paused"))
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	hashes, err := client.GetTorrentHashes(ctx)
+	if err != nil {
+		t.Fatalf("GetTorrentHashes: %v", err)
+	}
+
+	if len(hashes) != 2 || hashes[0] != hash1 || hashes[1] != hash2 {
+		t.Errorf("hashes: got %v, want [%s %s]", hashes, hash1, hash2)
+	}
+
+	for _, result := range []interface{}{nil, []string{}} {
+		result := result
+
+		server.Handle(deluge.GetSession, func([]json.RawMessage) (interface{}, error) { return result, nil })
+
+		if hashes, err = client.GetSessionState(ctx); err != nil || hashes == nil || len(hashes) != 0 {
+			t.Errorf("result %v: got %v (err: %v), want an empty list", result, hashes, err)
+		}
+	}
+}
+
+func TestRawURL(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	var paths []string
+
+	// Record the path of every request, then let the fake web UI answer it.
+	handler := server.Server.Config.Handler
+	server.Server.Config.Handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		server.Lock()
+		paths = append(paths, req.URL.RequestURI())
+		server.Unlock()
+		handler.ServeHTTP(resp, req)
+	})
+
+	tests := []struct {
+		url  string
+		raw  bool
+		want string
+	}{
+		{url: server.URL + "/custom/rpc", raw: true, want: "/custom/rpc"},
+		{url: server.URL + "/custom/rpc/", raw: true, want: "/custom/rpc/"},
+		{url: server.URL + "/rpc?key=value", raw: true, want: "/rpc?key=value"},
+		{url: server.URL + "/custom/rpc", raw: false, want: "/custom/rpc/json"},
+		{url: server.URL + "/json", raw: false, want: "/json"},
+		{url: server.URL + "/", raw: false, want: "/json"},
+	}
+
+	for _, test := range tests {
+		server.Lock()
+		paths = nil
+		server.Unlock()
+
+		config := server.Config()
+		config.URL = test.url
+		config.RawURL = test.raw
+
+		if _, err := deluge.New(context.Background(), config); err != nil {
+			t.Fatalf("%s: New: %v", test.url, err)
+		}
+
+		server.Lock()
+		for _, path := range paths {
+			if path != test.want {
+				t.Errorf("%s (raw: %v): request path %q, want %q", test.url, test.raw, path, test.want)
+			}
+		}
+		server.Unlock()
+	}
+}
+
+func TestUnlimitedSentAsInteger(t *testing.T) {
+	t.Parallel()
+
+	const hash = "1111111111111111111111111111111111111111"
+
+	tests := []struct {
+		version string
+		method  string
+		set     func(*deluge.Deluge, context.Context) error
+		want    string // The last param, as marshaled by the client.
+	}{
+		{
+			version: delugetest.Version2,
+			method:  deluge.SetTorrentOpts,
+			set:     func(d *deluge.Deluge, ctx context.Context) error { return d.SetMaxUploadSlots(ctx, hash, -1) },
+			want:    `{"max_upload_slots":-1}`,
+		},
+		{
+			version: delugetest.Version2,
+			method:  deluge.SetTorrentOpts,
+			set:     func(d *deluge.Deluge, ctx context.Context) error { return d.SetMaxConnections(ctx, hash, -1) },
+			want:    `{"max_connections":-1}`,
+		},
+		{
+			version: delugetest.Version1,
+			method:  deluge.SetMaxSlots,
+			set:     func(d *deluge.Deluge, ctx context.Context) error { return d.SetMaxUploadSlots(ctx, hash, -1) },
+			want:    `-1`,
+		},
+		{
+			version: delugetest.Version1,
+			method:  deluge.SetMaxConns,
+			set:     func(d *deluge.Deluge, ctx context.Context) error { return d.SetMaxConnections(ctx, hash, -1) },
+			want:    `-1`,
+		},
+	}
+
+	for _, test := range tests {
+		server := delugetest.NewServer(test.version)
+		defer server.Close()
+
+		var got string
+
+		server.Handle(test.method, func(params []json.RawMessage) (interface{}, error) {
+			got = string(params[len(params)-1])
+			return nil, nil
+		})
+
+		ctx := context.Background()
+
+		client, err := deluge.New(ctx, server.Config())
+		if err != nil {
+			t.Fatalf("%s: New: %v", test.version, err)
+		}
+
+		if err := test.set(client, ctx); err != nil {
+			t.Fatalf("%s %s: %v", test.version, test.method, err)
+		}
+
+		if got != test.want {
+			t.Errorf("%s %s: sent %s, want %s", test.version, test.method, got, test.want)
+		}
+
+		if err := client.SetMaxConnections(ctx, hash, -2); !errors.Is(err, deluge.ErrInvalidValue) {
+			t.Errorf("%s: -2: got %v, want %v", test.version, err, deluge.ErrInvalidValue)
+		}
+	}
+}
+
+func TestRemoveTorrentsBulkResult(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hash1 = "1111111111111111111111111111111111111111"
+		hash2 = "2222222222222222222222222222222222222222"
+	)
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	server.Handle(deluge.RemoveTorrents, func([]json.RawMessage) (interface{}, error) {
+		return [][]string{{hash2, "InvalidTorrentError: torrent_id not in session"}}, nil
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	failed, err := client.RemoveTorrents(ctx, []string{hash1, hash2}, false)
+	if err != nil {
+		t.Fatalf("RemoveTorrents: %v", err)
+	}
+
+	if len(failed) != 1 || !errors.Is(failed[hash2], deluge.ErrDelugeError) {
+		t.Errorf("failed: got %v, want only %s", failed, hash2)
+	}
+}
+
+func TestNullScalarResults(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	null := func([]json.RawMessage) (interface{}, error) { return nil, nil }
+	for _, method := range []string{deluge.GetListenPort, deluge.TestListenPort, deluge.GetExternalIP} {
+		server.Handle(method, null)
+	}
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if port, err := client.GetListenPort(ctx); err != nil || port != 0 {
+		t.Errorf("int: got %d (err: %v), want 0", port, err)
+	}
+
+	if open, err := client.TestListenPortOpen(ctx); err != nil || open {
+		t.Errorf("bool: got %v (err: %v), want false", open, err)
+	}
+
+	if address, err := client.GetExternalIP(ctx); err != nil || address != "" {
+		t.Errorf("string: got %q (err: %v), want empty", address, err)
+	}
+}
+
+func TestWaitForState(t *testing.T) {
+	t.Parallel()
+
+	const hash = "1111111111111111111111111111111111111111"
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	server.AddTorrent(hash, delugetest.Torrent(delugetest.Version2, hash, "test", "Seeding"))
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := client.WaitForState(ctx, hash, "Seeding", time.Millisecond); err != nil {
+		t.Errorf("existing torrent: %v", err)
+	}
+
+	missing := strings.Repeat("f", len(hash))
+	if err := client.WaitForState(ctx, missing, "Seeding", time.Millisecond); !errors.Is(err, deluge.ErrTorrentNotFound) {
+		t.Errorf("missing torrent: got %v, want %v", err, deluge.ErrTorrentNotFound)
+	}
+
+	if err := client.WaitForState(ctx, hash, "Seeding", 0); !errors.Is(err, deluge.ErrInvalidValue) {
+		t.Errorf("zero interval: got %v, want %v", err, deluge.ErrInvalidValue)
+	}
+}
+
+func TestWaitForStateRemoved(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	polls := 0
+
+	// The torrent downloads for two polls, then is removed; Deluge sends an empty status.
+	server.Handle(deluge.GetTorrentStat, func([]json.RawMessage) (interface{}, error) {
+		if polls++; polls > 2 {
+			return map[string]interface{}{}, nil
+		}
+
+		return map[string]interface{}{"state": "Downloading"}, nil
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = client.WaitForState(ctx, "1111111111111111111111111111111111111111", "Seeding", time.Millisecond)
+	if !errors.Is(err, deluge.ErrTorrentNotFound) {
+		t.Errorf("got %v, want %v", err, deluge.ErrTorrentNotFound)
+	}
+
+	if polls != 3 {
+		t.Errorf("polls: got %d, want 3", polls)
+	}
+}
+
+func TestWaitForStateCanceled(t *testing.T) {
+	t.Parallel()
+
+	const hash = "1111111111111111111111111111111111111111"
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	server.AddTorrent(hash, delugetest.Torrent(delugetest.Version2, hash, "test", "Downloading"))
+
+	client, err := deluge.New(context.Background(), server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := client.WaitForState(ctx, hash, "Seeding", 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRenameTorrentFallback(t *testing.T) {
+	t.Parallel()
+
+	const hash = "1111111111111111111111111111111111111111"
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	status := delugetest.Torrent(delugetest.Version2, hash, "old", "Seeding")
+	status["files"] = []map[string]interface{}{{"index": 0, "path": "old/file.mkv", "size": 1}}
+	server.AddTorrent(hash, status)
+
+	var renamed []json.RawMessage
+
+	server.Handle(deluge.SetTorrentOpts, func([]json.RawMessage) (interface{}, error) {
+		return nil, delugetest.ErrFake
+	})
+	server.Handle(deluge.RenameFolder, func(params []json.RawMessage) (interface{}, error) {
+		renamed = params
+		return nil, nil
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := client.RenameTorrent(ctx, hash, "new"); err != nil {
+		t.Fatalf("RenameTorrent: %v", err)
+	}
+
+	if len(renamed) != 3 || string(renamed[1]) != `"old/"` || string(renamed[2]) != `"new/"` {
+		t.Errorf("rename_folder params: got %s, want [%q, \"old/\", \"new/\"]", renamed, hash)
+	}
+
+	server.Lock()
+	status["files"] = []map[string]interface{}{{"index": 0, "path": "file.mkv", "size": 1}}
+	server.Unlock()
+
+	if err := client.RenameTorrent(ctx, hash, "new"); !errors.Is(err, deluge.ErrCannotRename) {
+		t.Errorf("no folder: got %v, want %v", err, deluge.ErrCannotRename)
+	}
+}
+
+func TestRegisterStatusKey(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hash = "1111111111111111111111111111111111111111"
+		key  = "deluge_test_extra"
+	)
+
+	deluge.RegisterStatusKey(key)
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	status := delugetest.Torrent(delugetest.Version2, hash, "test", "Seeding")
+	status[key] = "value"
+	server.AddTorrent(hash, status)
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	xfers, err := client.GetXfersContext(ctx)
+	if err != nil {
+		t.Fatalf("GetXfers: %v", err)
+	}
+
+	if got := string(xfers[hash].Extra[key]); got != `"value"` {
+		t.Errorf("GetXfers: got %s, want \"value\"", got)
+	}
+
+	compat, err := client.GetXfersCompatContext(ctx)
+	if err != nil {
+		t.Fatalf("GetXfersCompat: %v", err)
+	}
+
+	if got := string(compat[hash].Extra[key]); got != `"value"` {
+		t.Errorf("GetXfersCompat: got %s, want \"value\"", got)
+	}
+}
+
+func TestEventsData(t *testing.T) {
+	t.Parallel()
+
+	const hash = "1111111111111111111111111111111111111111"
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	sent := false
+
+	server.Handle(deluge.RegisterEvent, func([]json.RawMessage) (interface{}, error) { return nil, nil })
+	server.Handle(deluge.GetEvents, func([]json.RawMessage) (interface{}, error) {
+		if sent {
+			return nil, nil
+		}
+
+		sent = true
+
+		return [][]interface{}{{deluge.EventTorrentAdded, []interface{}{hash, false}}}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	events, err := client.Events(ctx, deluge.EventTorrentAdded)
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+
+	event := <-events
+	if event.Err != nil {
+		t.Fatalf("event error: %v", event.Err)
+	}
+
+	if added, ok := event.Data.(*deluge.TorrentAdded); !ok || added.Hash != hash {
+		t.Errorf("data: got %#v, want *TorrentAdded for %s", event.Data, hash)
+	}
+}
+
+func TestAddTorrentUpload(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hash = "1111111111111111111111111111111111111111"
+		path = "/tmp/delugeweb-test/test.torrent"
+	)
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	uploads := 0
+
+	// The fake web UI only speaks JSON-RPC; answer /upload here. The first upload
+	// is refused like an expired session, so the client must log in again.
+	handler := server.Server.Config.Handler
+	server.Server.Config.Handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/upload" {
+			handler.ServeHTTP(resp, req)
+			return
+		}
+
+		server.Lock()
+		uploads++
+		first := uploads == 1
+		server.Unlock()
+
+		if first {
+			http.Error(resp, "session expired", http.StatusForbidden)
+			return
+		}
+
+		_ = json.NewEncoder(resp).Encode(map[string]interface{}{"success": true, "files": []string{path}})
+	})
+
+	result := interface{}([][]interface{}{{true, hash}})
+
+	server.Handle(deluge.AddTorrents, func([]json.RawMessage) (interface{}, error) { return result, nil })
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	got, err := client.AddTorrentUpload(ctx, "test.torrent", []byte("d4:infod4:name4:testee"), nil)
+	if err != nil || got != hash {
+		t.Errorf("2.x result: got %q (err: %v), want %s", got, err, hash)
+	}
+
+	if uploads != 2 {
+		t.Errorf("uploads: got %d, want 2", uploads)
+	}
+
+	for _, test := range []struct {
+		result interface{}
+		fails  bool
+	}{
+		{result: true},
+		{result: false, fails: true},
+		{result: nil, fails: true},
+		{result: []interface{}{}, fails: true},
+	} {
+		result = test.result
+
+		if got, err = client.AddTorrentUpload(ctx, "test.torrent", []byte("d4:infod4:name4:testee"), nil); got != "" {
+			t.Errorf("result %v: got hash %q, want none", test.result, got)
+		} else if test.fails != errors.Is(err, deluge.ErrDelugeError) {
+			t.Errorf("result %v: got error %v, want an error: %v", test.result, err, test.fails)
+		}
+	}
+}
+
+func TestBlocklistConfig(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	// The Blocklist plugin's DEFAULT_PREFS, with a compressed list.
+	server.Handle(deluge.GetBlocklist, func([]json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"url": "https://example.com/list.gz", "load_on_start": false, "check_after_days": 4,
+			"list_compression": "GZip", "list_type": "", "last_update": 1700000000.5, "list_size": 0,
+			"timeout": 180, "try_times": 3, "whitelisted": []string{},
+		}, nil
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	config, err := client.GetBlocklistConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetBlocklistConfig: %v", err)
+	}
+
+	if config.ListCompression != "GZip" || config.LastUpdate != 1700000000.5 || config.CheckAfterDays != 4 {
+		t.Errorf("config: got %+v", config)
+	}
+}
+
+func TestSetBlocklistConfig(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	var sent map[string]json.RawMessage
+
+	server.Handle(deluge.SetBlocklist, func(params []json.RawMessage) (interface{}, error) {
+		return nil, json.Unmarshal(params[0], &sent)
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = client.SetBlocklistConfig(ctx, &deluge.BlocklistConfig{URL: "https://example.com/list", LastUpdate: 1, ListSize: 1})
+	if err != nil {
+		t.Fatalf("SetBlocklistConfig: %v", err)
+	}
+
+	for _, key := range []string{"last_update", "list_size"} {
+		if value, ok := sent[key]; ok {
+			t.Errorf("%s: sent %s, want it left out", key, value)
+		}
+	}
+
+	if got := string(sent["whitelisted"]); got != "[]" {
+		t.Errorf("whitelisted: sent %s, want []", got)
+	}
+}
+
+func TestScheduleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	var stored json.RawMessage // The plugin's config, as set_config received it.
+
+	server.Handle(deluge.SetScheduler, func(params []json.RawMessage) (interface{}, error) {
+		stored = params[0]
+		return nil, nil
+	})
+	server.Handle(deluge.GetScheduler, func([]json.RawMessage) (interface{}, error) {
+		return stored, nil
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	grid := make([][]int, 7)
+	for day := range grid {
+		grid[day] = make([]int, 24)
+		for hour := range grid[day] {
+			grid[day][hour] = (day + hour) % 3
+		}
+	}
+
+	grid[6][23] = deluge.SchedulePaused // Sunday night, the last cell.
+
+	if err := client.SetSchedule(ctx, grid); err != nil {
+		t.Fatalf("SetSchedule: %v", err)
+	}
+
+	// The plugin reads button_state[hour][weekday].
+	var config struct {
+		ButtonState [][]int `json:"button_state"`
+	}
+
+	if err := json.Unmarshal(stored, &config); err != nil {
+		t.Fatalf("decoding set_config params: %v", err)
+	}
+
+	if len(config.ButtonState) != 24 || len(config.ButtonState[0]) != 7 {
+		t.Fatalf("button_state: got %d rows of %d, want 24 rows of 7", len(config.ButtonState), len(config.ButtonState[0]))
+	}
+
+	if got := config.ButtonState[23][6]; got != deluge.SchedulePaused {
+		t.Errorf("button_state[23][6]: got %d, want %d", got, deluge.SchedulePaused)
+	}
+
+	got, err := client.GetSchedule(ctx)
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, grid) {
+		t.Errorf("GetSchedule: got %v, want %v", got, grid)
+	}
+
+	if err := client.SetSchedule(ctx, config.ButtonState); !errors.Is(err, deluge.ErrInvalidSchedule) {
+		t.Errorf("SetSchedule(24x7): got %v, want %v", err, deluge.ErrInvalidSchedule)
+	}
+}
+
+func TestLibrarySize(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	const count = 1201 // Three batches.
+
+	for i := 0; i < count; i++ {
+		hash := fmt.Sprintf("%040x", i)
+		status := delugetest.Torrent(delugetest.Version2, hash, hash, "Seeding")
+		status["total_size"] = 100
+		status["total_done"] = 100 * (i % 2)
+		server.AddTorrent(hash, status)
+	}
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	size, done, err := client.LibrarySize(ctx)
+	if err != nil {
+		t.Fatalf("LibrarySize: %v", err)
+	}
+
+	if size != 100*count || done != 100*(count/2) {
+		t.Errorf("got size %d and done %d, want %d and %d", size, done, 100*count, 100*(count/2))
+	}
+
+	server.Lock()
+	batches := 0
+
+	for _, method := range server.Calls {
+		if method == deluge.GetAllTorrents {
+			batches++
+		}
+	}
+	server.Unlock()
+
+	if batches != 3 {
+		t.Errorf("get_torrents_status calls: got %d, want 3", batches)
+	}
+
+	stop := errors.New("stop")
+	seen := 0
+
+	err = client.RangeXfers(ctx, []string{"name"}, func(string, *deluge.XferStatusCompat) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("stopping early: got %v after %d torrents, want %v after 1", err, seen, stop)
+	}
+}
+
+func TestSetAutoAddWatchDir(t *testing.T) {
+	t.Parallel()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	dirs := map[string]map[string]interface{}{"1": {
+		"path": "/watch", "abspath": "/watch", "enabled": true, "label": "tv", "label_toggle": true,
+		"download_location": "/tv", "download_location_toggle": true, "add_paused": false,
+	}}
+
+	server.Handle(deluge.GetWatchDirs, func([]json.RawMessage) (interface{}, error) { return dirs, nil })
+	// Like the plugin, set_options updates the folder with the options it is sent.
+	server.Handle(deluge.SetWatchDir, func(params []json.RawMessage) (interface{}, error) {
+		var (
+			id      string
+			options map[string]interface{}
+		)
+
+		_ = json.Unmarshal(params[0], &id)
+		_ = json.Unmarshal(params[1], &options)
+
+		for key, value := range options {
+			dirs[id][key] = value
+		}
+
+		return nil, nil
+	})
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, server.Config())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	current, err := client.GetAutoAddWatchDirs(ctx)
+	if err != nil {
+		t.Fatalf("GetAutoAddWatchDirs: %v", err)
+	}
+
+	// Change only the label, and leave the path out.
+	update := current["1"]
+	update.Label = "movies"
+	update.Path, update.AbsPath = "", ""
+
+	if err := client.SetAutoAddWatchDir(ctx, "1", update); err != nil {
+		t.Fatalf("SetAutoAddWatchDir: %v", err)
+	}
+
+	got, err := client.GetAutoAddWatchDirs(ctx)
+	if err != nil {
+		t.Fatalf("GetAutoAddWatchDirs: %v", err)
+	}
+
+	want := current["1"]
+	want.Label = "movies"
+
+	if got["1"] != want {
+		t.Errorf("got %+v, want %+v", got["1"], want)
+	}
+}
@@ -0,0 +1,319 @@
+// Package delugerpc is a client for the native Deluge daemon RPC protocol.
+// It talks directly to deluged, usually on port 58846, so the web UI is not required.
+// Requests are rencode-encoded, zlib-compressed and sent over TLS. Only the Deluge 2.x
+// protocol is supported; Deluge 1.x frames messages differently.
+//
+// Client has a subset of the methods on deluge.Deluge, with the same names, signatures
+// and errors, so code that needs only those can switch between the web UI and the daemon
+// with an interface. Adds report duplicates with deluge.ErrAlreadyAdded, and labels follow
+// Config.AutoCreateLabels like deluge.Config's. Web UI methods, like UpdateUI and
+// AddTorrentUpload, have no daemon equivalent. Plugin methods fail with ErrDaemon,
+// not deluge.ErrPluginNotInstalled, when the plugin is not enabled.
+package delugerpc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"golift.io/deluge"
+)
+
+// DefaultPort is the port deluged listens on by default.
+const DefaultPort = "58846"
+
+// protocolVersion is the first byte of every Deluge 2.x message.
+const protocolVersion = 1
+
+// RPC message types.
+const (
+	rpcResponse = 1
+	rpcError    = 2
+	rpcEvent    = 3
+)
+
+// Positions of the fields in an RPC message: [type, request id, result] for a response,
+// and [type, request id, exception type, exception message, traceback] for an error.
+const (
+	msgKind   = 0
+	msgID     = 1
+	msgResult = 2
+	msgDetail = 3
+)
+
+// headerSize is the protocol version byte plus the 4-byte body length that start every message.
+const headerSize = 5
+
+// maxMessageBytes protects against a broken server announcing a huge message.
+const maxMessageBytes = 256 * 1024 * 1024
+
+// Custom errors.
+var (
+	ErrUnsupportedType = fmt.Errorf("cannot rencode type")
+	ErrInvalidData     = fmt.Errorf("invalid rencode data")
+	ErrProtocol        = fmt.Errorf("unexpected daemon message")
+	ErrDaemon          = fmt.Errorf("daemon error")
+	ErrClosed          = fmt.Errorf("connection to deluged is closed")
+)
+
+// Config is the data needed to connect to a Deluge daemon.
+// Address is host:port; the port defaults to DefaultPort. The daemon normally uses
+// a self-signed certificate, so the default TLS config skips verification.
+// Provide a TLSConfig to verify the daemon's certificate.
+type Config struct {
+	Address   string        `json:"address" toml:"address" xml:"address" yaml:"address"`
+	Username  string        `json:"username" toml:"username" xml:"username" yaml:"username"`
+	Password  string        `json:"password" toml:"password" xml:"password" yaml:"password"`
+	Timeout   time.Duration `json:"timeout" toml:"timeout" xml:"timeout" yaml:"timeout"`
+	TLSConfig *tls.Config   `json:"-" toml:"-" xml:"-" yaml:"-"`
+	// AutoCreateLabels creates missing labels when applying them, instead of returning
+	// deluge.ErrUnknownLabel.
+	AutoCreateLabels bool `json:"auto_create_labels" toml:"auto_create_labels" xml:"auto_create_labels" yaml:"auto_create_labels"`
+}
+
+// Client is a logged-in connection to a Deluge daemon. Calls are safe for concurrent use,
+// but are sent one at a time. Events sent by the daemon are discarded.
+// A call that fails to write or read, or is canceled mid-call, closes the connection,
+// because the daemon may still send its reply. Later calls return ErrClosed; create
+// a new Client to reconnect.
+type Client struct {
+	conn      net.Conn
+	lock      sync.Mutex
+	id        int64
+	err       error // Set when the connection is closed; returned by every later call.
+	addLabel  bool
+	AuthLevel int64 // The auth level the daemon granted at login.
+}
+
+// New connects to a Deluge daemon and logs in.
+func New(ctx context.Context, config *Config) (*Client, error) {
+	address := config.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultPort)
+	}
+
+	tlsConfig := config.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // deluged uses a self-signed certificate.
+	}
+
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: config.Timeout}, Config: tlsConfig}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing deluged: %w", err)
+	}
+
+	client := &Client{conn: conn, addLabel: config.AutoCreateLabels}
+
+	result, err := client.Call(ctx, "daemon.login", []interface{}{config.Username, config.Password},
+		map[string]interface{}{"client_version": "golift.io/deluge"})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %v", deluge.ErrAuthFailed, err)
+	}
+
+	client.AuthLevel, _ = result.(int64)
+
+	return client, nil
+}
+
+// Close closes the connection to the daemon.
+func (c *Client) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.err != nil {
+		return nil
+	}
+
+	c.err = ErrClosed
+
+	return c.conn.Close() //nolint:wrapcheck
+}
+
+// poison closes the connection after a failed call, and records why. Lock the client first.
+func (c *Client) poison(err error) {
+	c.err = fmt.Errorf("%w: %v", ErrClosed, err)
+	_ = c.conn.Close()
+}
+
+// Call runs a daemon method, like core.get_torrents_status, and returns its decoded result.
+// Errors raised by the daemon wrap ErrDaemon, and include the exception type and message.
+func (c *Client) Call(ctx context.Context, method string, args []interface{},
+	kwargs map[string]interface{},
+) (interface{}, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if args == nil {
+		args = []interface{}{}
+	}
+
+	if kwargs == nil {
+		kwargs = map[string]interface{}{}
+	}
+
+	c.id++
+
+	deadline, _ := ctx.Deadline()
+	_ = c.conn.SetDeadline(deadline)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	if err := c.send([]interface{}{[]interface{}{c.id, method, args, kwargs}}); err != nil {
+		return nil, c.failed(ctx, err)
+	}
+
+	for {
+		msg, err := c.receive()
+		if err != nil {
+			return nil, c.failed(ctx, err)
+		}
+
+		if len(msg) <= msgID {
+			return nil, fmt.Errorf("%w: %v", ErrProtocol, msg)
+		}
+
+		if kind, _ := msg[msgKind].(int64); kind == rpcEvent {
+			continue
+		}
+
+		if id, _ := msg[msgID].(int64); id != c.id {
+			continue // Not a reply to this call.
+		}
+
+		return parseReply(method, msg)
+	}
+}
+
+// failed poisons the connection after an I/O error, and returns the error,
+// or the context's error if the call was canceled.
+func (c *Client) failed(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	c.poison(err)
+
+	return err
+}
+
+// parseReply returns the result of an RPC response message, or the error in an RPC error message.
+func parseReply(method string, msg []interface{}) (interface{}, error) {
+	kind, _ := msg[msgKind].(int64)
+
+	switch {
+	case kind == rpcResponse && len(msg) > msgResult:
+		return msg[msgResult], nil
+	case kind == rpcError && len(msg) > msgDetail:
+		return nil, fmt.Errorf("%w: %s: %v: %v", ErrDaemon, method, msg[msgResult], msg[msgDetail])
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrProtocol, msg)
+	}
+}
+
+// send writes a message: the protocol version, the body length, and the zlib-compressed body.
+func (c *Client) send(value interface{}) error {
+	data, err := Encode(value)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+
+	writer := zlib.NewWriter(&body)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+
+	header := make([]byte, headerSize)
+	header[0] = protocolVersion
+	binary.BigEndian.PutUint32(header[1:], uint32(body.Len()))
+
+	if _, err := c.conn.Write(append(header, body.Bytes()...)); err != nil {
+		return fmt.Errorf("writing to deluged: %w", err)
+	}
+
+	return nil
+}
+
+// receive reads one message from the daemon.
+func (c *Client) receive() ([]interface{}, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, fmt.Errorf("reading from deluged: %w", err)
+	}
+
+	if header[0] != protocolVersion {
+		return nil, fmt.Errorf("%w: protocol version %d", ErrProtocol, header[0])
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxMessageBytes {
+		return nil, fmt.Errorf("%w: message is %d bytes", ErrProtocol, size)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, body); err != nil {
+		return nil, fmt.Errorf("reading from deluged: %w", err)
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing message: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing message: %w", err)
+	}
+
+	value, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrProtocol, value)
+	}
+
+	return msg, nil
+}
+
+// decodeInto converts a decoded result into v, using the json tags on v's type.
+// This lets daemon results fill the same types the web UI client returns.
+func decodeInto(result interface{}, v interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(result): %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("json.Unmarshal(result): %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package delugerpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"golift.io/deluge"
+)
+
+// pipe returns a client connected to a fake daemon. The daemon side uses the same framing,
+// so its receive returns [[id, method, args, kwargs]] and its send writes replies.
+func pipe(t *testing.T) (*Client, *Client) {
+	t.Helper()
+
+	clientSide, daemonSide := net.Pipe()
+	client, daemon := &Client{conn: clientSide}, &Client{conn: daemonSide}
+
+	t.Cleanup(func() {
+		client.Close()
+		daemon.Close()
+	})
+
+	return client, daemon
+}
+
+// request reads one call from the client and returns its id and method.
+func request(t *testing.T, daemon *Client) (int64, string) {
+	t.Helper()
+
+	msg, err := daemon.receive()
+	if err != nil {
+		t.Errorf("daemon receive: %v", err)
+		return 0, ""
+	}
+
+	call, _ := msg[0].([]interface{})
+	id, _ := call[0].(int64)
+	method, _ := call[1].(string)
+
+	return id, method
+}
+
+func TestCall(t *testing.T) {
+	t.Parallel()
+
+	client, daemon := pipe(t)
+
+	go func() {
+		id, method := request(t, daemon)
+		if method != "core.get_session_state" {
+			t.Errorf("method: got %q, want core.get_session_state", method)
+		}
+
+		// An event and a stale reply come first; the client skips both.
+		_ = daemon.send([]interface{}{rpcEvent, "TorrentAddedEvent", []interface{}{"abc"}})
+		_ = daemon.send([]interface{}{rpcResponse, id - 1, "stale"})
+		_ = daemon.send([]interface{}{rpcResponse, id, []interface{}{"abc", "def"}})
+
+		id, _ = request(t, daemon)
+		_ = daemon.send([]interface{}{rpcError, id, "KeyError", "no such torrent", "traceback"})
+	}()
+
+	hashes, err := client.GetSessionState(context.Background())
+	if err != nil {
+		t.Fatalf("GetSessionState: %v", err)
+	}
+
+	if want := []string{"abc", "def"}; !reflect.DeepEqual(hashes, want) {
+		t.Errorf("hashes: got %v, want %v", hashes, want)
+	}
+
+	if _, err := client.GetXfer(context.Background(), "abc"); !errors.Is(err, ErrDaemon) {
+		t.Errorf("daemon error: got %v, want ErrDaemon", err)
+	}
+}
+
+func TestCallCanceledClosesConnection(t *testing.T) {
+	t.Parallel()
+
+	client, daemon := pipe(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		request(t, daemon) // Never reply, like a slow daemon.
+		cancel()
+	}()
+
+	if _, err := client.GetSessionState(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled call: got %v, want context.Canceled", err)
+	}
+
+	// The reply to the canceled call could still arrive, so the connection is not reused.
+	if _, err := client.GetSessionState(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("next call: got %v, want ErrClosed", err)
+	}
+}
+
+func TestCallAfterClose(t *testing.T) {
+	t.Parallel()
+
+	client, _ := pipe(t)
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := client.Call(context.Background(), "daemon.info", nil, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("got %v, want ErrClosed", err)
+	}
+}
+
+// mirrored lists methods that Client shares with deluge.Deluge, so code can use either.
+type mirrored interface {
+	DaemonInfo(ctx context.Context) (*deluge.DaemonInfo, error)
+	GetXfersCompatContext(ctx context.Context) (map[string]*deluge.XferStatusCompat, error)
+	GetXfer(ctx context.Context, hash string) (*deluge.XferStatusCompat, error)
+	Exists(ctx context.Context, hash string) (bool, error)
+	GetTorrentHashes(ctx context.Context) ([]string, error)
+	GetConfigValues(ctx context.Context, keys []string) (map[string]json.RawMessage, error)
+	GetFreeSpace(ctx context.Context, path string) (int64, error)
+	AddTorrentMagnet(ctx context.Context, magnetURI string, opts *deluge.AddOptions) (string, error)
+	AddTorrentFile(ctx context.Context, filename string, contents []byte, opts *deluge.AddOptions) (string, error)
+	PauseTorrents(ctx context.Context, hashes []string) error
+	MoveStorage(ctx context.Context, hashes []string, dest string) error
+	QueueTop(ctx context.Context, hashes []string) error
+	RemoveTorrent(ctx context.Context, hash string, removeData bool) (bool, error)
+	RemoveTorrents(ctx context.Context, hashes []string, removeData bool) (map[string]error, error)
+	AddLabel(ctx context.Context, label string) error
+	SetTorrentLabel(ctx context.Context, hash, label string) error
+}
+
+var (
+	_ mirrored = (*Client)(nil)
+	_ mirrored = (*deluge.Deluge)(nil)
+)
+
+func TestRemoveTorrents(t *testing.T) {
+	t.Parallel()
+
+	client, daemon := pipe(t)
+
+	go func() {
+		id, _ := request(t, daemon)
+		_ = daemon.send([]interface{}{rpcResponse, id, []interface{}{[]interface{}{"abc", "torrent not found"}}})
+	}()
+
+	failed, err := client.RemoveTorrents(context.Background(), []string{"abc", "def"}, false)
+	if err != nil {
+		t.Fatalf("RemoveTorrents: %v", err)
+	}
+
+	if len(failed) != 1 || !errors.Is(failed["abc"], deluge.ErrDelugeError) {
+		t.Errorf("got %v, want only abc to fail with ErrDelugeError", failed)
+	}
+}
+
+func TestAddTorrentDuplicate(t *testing.T) {
+	t.Parallel()
+
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+
+	client, daemon := pipe(t)
+
+	go func() {
+		id, _ := request(t, daemon)
+		_ = daemon.send([]interface{}{rpcError, id, "AddTorrentError",
+			"Torrent already in session (" + hash + ").", "traceback"})
+	}()
+
+	got, err := client.AddTorrentMagnet(context.Background(), "magnet:?xt=urn:btih:"+hash,
+		&deluge.AddOptions{Label: "tv"})
+	if !errors.Is(err, deluge.ErrAlreadyAdded) {
+		t.Errorf("error: got %v, want %v", err, deluge.ErrAlreadyAdded)
+	}
+
+	if got != hash {
+		t.Errorf("hash: got %q, want %q", got, hash)
+	}
+}
+
+func TestSetTorrentLabel(t *testing.T) {
+	t.Parallel()
+
+	for _, autoCreate := range []bool{false, true} {
+		client, daemon := pipe(t)
+		client.addLabel = autoCreate
+
+		methods := make(chan []string)
+
+		go func() {
+			var called []string
+
+			for {
+				msg, err := daemon.receive()
+				if err != nil {
+					break // The client closed the connection.
+				}
+
+				call, _ := msg[0].([]interface{})
+				id, _ := call[0].(int64)
+				method, _ := call[1].(string)
+
+				called = append(called, method)
+
+				var result interface{}
+				if method == deluge.GetLabels {
+					result = []interface{}{"movies"}
+				}
+
+				_ = daemon.send([]interface{}{rpcResponse, id, result})
+			}
+
+			methods <- called
+		}()
+
+		err := client.SetTorrentLabel(context.Background(), "abc", "tv")
+		client.Close() // Ends the daemon's loop.
+
+		want := []string{deluge.GetLabels, deluge.AddLabel, deluge.SetLabel}
+		if !autoCreate {
+			want = want[:1]
+
+			if !errors.Is(err, deluge.ErrUnknownLabel) {
+				t.Errorf("error: got %v, want %v", err, deluge.ErrUnknownLabel)
+			}
+		} else if err != nil {
+			t.Errorf("SetTorrentLabel with AutoCreateLabels: %v", err)
+		}
+
+		if got := <-methods; !reflect.DeepEqual(got, want) {
+			t.Errorf("auto create %v: got calls %v, want %v", autoCreate, got, want)
+		}
+	}
+}
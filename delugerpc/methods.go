@@ -0,0 +1,390 @@
+package delugerpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golift.io/deluge"
+)
+
+// get calls a method with positional args, and decodes its result into v.
+// A None result leaves v unchanged.
+func (c *Client) get(ctx context.Context, method string, v interface{}, args ...interface{}) error {
+	result, err := c.Call(ctx, method, args, nil)
+	if err != nil {
+		return fmt.Errorf("call(%s): %w", method, err)
+	}
+
+	if v == nil || result == nil {
+		return nil
+	}
+
+	return decodeInto(result, v)
+}
+
+// DaemonInfo returns version and network details for the daemon. The details are requested
+// one after another. This only returns an error if the context is canceled; failed requests
+// are recorded in DaemonInfo.Errors.
+func (c *Client) DaemonInfo(ctx context.Context) (*deluge.DaemonInfo, error) {
+	info := &deluge.DaemonInfo{Errors: make(map[string]error)}
+
+	if err := c.get(ctx, deluge.GetDaemonInfo, &info.Version); err != nil {
+		info.Errors["version"] = err
+	}
+
+	if err := c.get(ctx, deluge.GetLibtorrent, &info.LibtorrentVersion); err != nil {
+		info.Errors["libtorrent"] = err
+	}
+
+	var err error
+	if info.ListenPort, err = c.GetListenPort(ctx); err != nil {
+		info.Errors["listen_port"] = err
+	}
+
+	if value, err := c.GetConfigValue(ctx, "listen_interface"); err != nil {
+		info.Errors["listen_interface"] = err
+	} else {
+		_ = json.Unmarshal(value, &info.ListenInterface)
+	}
+
+	return info, ctx.Err()
+}
+
+// GetXfersCompatContext gets all the transfers, with every status key.
+func (c *Client) GetXfersCompatContext(ctx context.Context) (map[string]*deluge.XferStatusCompat, error) {
+	return c.GetXfersCompatFields(ctx, nil, nil)
+}
+
+// GetXfersCompatFields gets the transfers matching filter, populating only the requested
+// status keys. A nil filter and nil keys return every transfer with every key.
+func (c *Client) GetXfersCompatFields(ctx context.Context,
+	filter map[string]interface{}, keys []string,
+) (map[string]*deluge.XferStatusCompat, error) {
+	if filter == nil {
+		filter = map[string]interface{}{}
+	}
+
+	if keys == nil {
+		keys = []string{}
+	}
+
+	xfers := make(map[string]*deluge.XferStatusCompat)
+
+	if err := c.get(ctx, deluge.GetAllTorrents, &xfers, filter, keys); err != nil {
+		return nil, err
+	}
+
+	return xfers, nil
+}
+
+// GetXfer returns a single transfer, with every status key.
+// Returns deluge.ErrTorrentNotFound if the daemon does not have the torrent.
+func (c *Client) GetXfer(ctx context.Context, hash string) (*deluge.XferStatusCompat, error) {
+	result, err := c.Call(ctx, deluge.GetTorrentStat, []interface{}{hash, []string{}}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("call(%s): %w", deluge.GetTorrentStat, err)
+	}
+
+	// The daemon returns an empty status, not an error, for an unknown hash.
+	if status, _ := result.(map[string]interface{}); len(status) == 0 {
+		return nil, fmt.Errorf("%w: %s", deluge.ErrTorrentNotFound, hash)
+	}
+
+	var xfer deluge.XferStatusCompat
+	if err := decodeInto(result, &xfer); err != nil {
+		return nil, err
+	}
+
+	return &xfer, nil
+}
+
+// Exists returns true if a torrent with the provided info hash is in the daemon.
+func (c *Client) Exists(ctx context.Context, hash string) (bool, error) {
+	hash = strings.ToLower(hash)
+
+	xfers, err := c.GetXfersCompatFields(ctx, map[string]interface{}{"id": []string{hash}}, []string{"hash"})
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := xfers[hash]
+
+	return ok, nil
+}
+
+// GetSessionState returns the hash of every torrent in the daemon.
+func (c *Client) GetSessionState(ctx context.Context) ([]string, error) {
+	hashes := []string{}
+
+	if err := c.get(ctx, deluge.GetSession, &hashes); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// GetTorrentHashes returns the hash of every torrent in the daemon.
+func (c *Client) GetTorrentHashes(ctx context.Context) ([]string, error) {
+	return c.GetSessionState(ctx)
+}
+
+// GetConfigValues returns the raw values for the requested daemon config keys.
+// Keys the daemon does not know are missing from the returned map.
+func (c *Client) GetConfigValues(ctx context.Context, keys []string) (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage)
+
+	if err := c.get(ctx, deluge.GetConfigVals, &values, keys); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetConfigValue returns the raw value for a single daemon config key, like download_location.
+// Returns deluge.ErrConfigKeyNotFound if the daemon does not return the key.
+func (c *Client) GetConfigValue(ctx context.Context, key string) (json.RawMessage, error) {
+	values, err := c.GetConfigValues(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", deluge.ErrConfigKeyNotFound, key)
+	}
+
+	return value, nil
+}
+
+// GetListenPort returns the port the daemon listens on for incoming peer connections.
+func (c *Client) GetListenPort(ctx context.Context) (int, error) {
+	var port int
+
+	if err := c.get(ctx, deluge.GetListenPort, &port); err != nil {
+		return 0, err
+	}
+
+	return port, nil
+}
+
+// GetFreeSpace returns the free space, in bytes, at a path on the daemon's host.
+// An empty path checks the default download location.
+func (c *Client) GetFreeSpace(ctx context.Context, path string) (int64, error) {
+	var (
+		free int64
+		args []interface{}
+	)
+
+	if path != "" {
+		args = append(args, path)
+	}
+
+	if err := c.get(ctx, deluge.GetFreeSpace, &free, args...); err != nil {
+		return 0, err
+	}
+
+	return free, nil
+}
+
+// GetInstalledPlugins returns the plugins installed in the daemon.
+func (c *Client) GetInstalledPlugins(ctx context.Context) ([]string, error) {
+	plugins := []string{}
+
+	if err := c.get(ctx, deluge.GetPlugins, &plugins); err != nil {
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+// GetEnabledPlugins returns the plugins enabled in the daemon.
+func (c *Client) GetEnabledPlugins(ctx context.Context) ([]string, error) {
+	plugins := []string{}
+
+	if err := c.get(ctx, deluge.GetEnabled, &plugins); err != nil {
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+// AddTorrentMagnet adds a torrent from a magnet link, and returns its hash. opts may be nil.
+func (c *Client) AddTorrentMagnet(ctx context.Context, magnetURI string, opts *deluge.AddOptions) (string, error) {
+	return c.addTorrent(ctx, deluge.AddMagnet, opts, magnetURI)
+}
+
+// AddTorrentURL has the daemon download a .torrent file from url and add it, and returns its hash.
+// opts may be nil.
+func (c *Client) AddTorrentURL(ctx context.Context, url string, opts *deluge.AddOptions) (string, error) {
+	return c.addTorrent(ctx, deluge.AddTorrentURL, opts, url)
+}
+
+// AddTorrentFile adds a torrent from the contents of a .torrent file, and returns its hash.
+// filename is only used for display and logs. opts may be nil.
+func (c *Client) AddTorrentFile(ctx context.Context, filename string, contents []byte,
+	opts *deluge.AddOptions,
+) (string, error) {
+	if len(contents) == 0 {
+		return "", fmt.Errorf("%w: empty torrent file %s", deluge.ErrInvalidValue, filename)
+	}
+
+	return c.addTorrent(ctx, deluge.AddTorrentFile, opts, filename, base64.StdEncoding.EncodeToString(contents))
+}
+
+// addTorrent calls one of the core.add_torrent_* methods with args followed by the
+// options from opts, applies the label from opts, and returns the new torrent's hash.
+// Adding a torrent the daemon already has returns the existing hash with deluge.ErrAlreadyAdded.
+func (c *Client) addTorrent(ctx context.Context, method string, opts *deluge.AddOptions,
+	args ...interface{},
+) (string, error) {
+	var hash string
+	if err := c.get(ctx, method, &hash, append(args, opts.Options())...); err != nil {
+		if hash := deluge.DuplicateHash(err.Error()); hash != "" {
+			return hash, fmt.Errorf("%w: %s", deluge.ErrAlreadyAdded, hash)
+		}
+
+		return "", err
+	}
+
+	if opts == nil || opts.Label == "" || hash == "" {
+		return hash, nil
+	}
+
+	return hash, c.SetTorrentLabel(ctx, hash, opts.Label)
+}
+
+// PauseTorrent pauses a single torrent.
+func (c *Client) PauseTorrent(ctx context.Context, hash string) error {
+	return c.PauseTorrents(ctx, []string{hash})
+}
+
+// ResumeTorrent resumes a single torrent.
+func (c *Client) ResumeTorrent(ctx context.Context, hash string) error {
+	return c.ResumeTorrents(ctx, []string{hash})
+}
+
+// PauseTorrents pauses the provided torrents.
+func (c *Client) PauseTorrents(ctx context.Context, hashes []string) error {
+	return c.get(ctx, deluge.PauseTorrents, nil, hashes)
+}
+
+// ResumeTorrents resumes the provided torrents.
+func (c *Client) ResumeTorrents(ctx context.Context, hashes []string) error {
+	return c.get(ctx, deluge.ResumeTorrents, nil, hashes)
+}
+
+// PauseSession pauses the whole libtorrent session.
+func (c *Client) PauseSession(ctx context.Context) error {
+	return c.get(ctx, deluge.PauseSession, nil)
+}
+
+// ResumeSession resumes the whole libtorrent session.
+func (c *Client) ResumeSession(ctx context.Context) error {
+	return c.get(ctx, deluge.ResumeSession, nil)
+}
+
+// MoveStorage moves the data for the provided torrents to a new location on the daemon's host.
+func (c *Client) MoveStorage(ctx context.Context, hashes []string, dest string) error {
+	return c.get(ctx, deluge.MoveStorage, nil, hashes, dest)
+}
+
+// QueueTop moves torrents to the top of the queue.
+func (c *Client) QueueTop(ctx context.Context, hashes []string) error {
+	return c.get(ctx, deluge.QueueTop, nil, hashes)
+}
+
+// QueueUp moves torrents up one position in the queue.
+func (c *Client) QueueUp(ctx context.Context, hashes []string) error {
+	return c.get(ctx, deluge.QueueUp, nil, hashes)
+}
+
+// QueueDown moves torrents down one position in the queue.
+func (c *Client) QueueDown(ctx context.Context, hashes []string) error {
+	return c.get(ctx, deluge.QueueDown, nil, hashes)
+}
+
+// QueueBottom moves torrents to the bottom of the queue.
+func (c *Client) QueueBottom(ctx context.Context, hashes []string) error {
+	return c.get(ctx, deluge.QueueBottom, nil, hashes)
+}
+
+// RemoveTorrent removes a torrent, and its data if removeData is true.
+// Returns true if the daemon reports the torrent was removed.
+func (c *Client) RemoveTorrent(ctx context.Context, hash string, removeData bool) (bool, error) {
+	var removed bool
+
+	if err := c.get(ctx, deluge.RemoveTorrent, &removed, hash, removeData); err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// RemoveTorrents removes torrents, and their data if removeData is true. Failures are returned
+// per hash; hashes missing from the map were removed. The error is only non-nil if the call failed.
+func (c *Client) RemoveTorrents(ctx context.Context, hashes []string, removeData bool) (map[string]error, error) {
+	result, err := c.Call(ctx, deluge.RemoveTorrents, []interface{}{hashes, removeData}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("call(%s): %w", deluge.RemoveTorrents, err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal(result): %w", err)
+	}
+
+	return deluge.ParseBulkResult(data), nil
+}
+
+// GetLabels returns every label in the Label plugin.
+func (c *Client) GetLabels(ctx context.Context) ([]string, error) {
+	labels := []string{}
+
+	if err := c.get(ctx, deluge.GetLabels, &labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// AddLabel creates a new label in the Label plugin. Labels must be lowercase.
+func (c *Client) AddLabel(ctx context.Context, label string) error {
+	return c.get(ctx, deluge.AddLabel, nil, label)
+}
+
+// SetTorrentLabel applies a label to a torrent; an empty label removes the torrent's label.
+// Deluge only applies labels that already exist. A missing label returns deluge.ErrUnknownLabel,
+// unless Config.AutoCreateLabels is set, which creates it with label.add first.
+func (c *Client) SetTorrentLabel(ctx context.Context, hash, label string) error {
+	if label != "" {
+		labels, err := c.GetLabels(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !contains(labels, label) {
+			if !c.addLabel {
+				return fmt.Errorf("%w: %s", deluge.ErrUnknownLabel, label)
+			}
+
+			if err := c.AddLabel(ctx, label); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.get(ctx, deluge.SetLabel, nil, hash, label)
+}
+
+func contains(list []string, item string) bool {
+	for _, have := range list {
+		if have == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,373 @@
+package delugerpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// rencode type codes, from the reference rencode implementation used by Deluge.
+const (
+	chrList    = 59
+	chrDict    = 60
+	chrInt     = 61
+	chrInt1    = 62
+	chrInt2    = 63
+	chrInt4    = 64
+	chrInt8    = 65
+	chrFloat32 = 66
+	chrFloat64 = 44
+	chrTrue    = 67
+	chrFalse   = 68
+	chrNone    = 69
+	chrTerm    = 127
+
+	intPosFixedStart = 0
+	intPosFixedCount = 44
+	dictFixedStart   = 102
+	dictFixedCount   = 25
+	intNegFixedStart = 70
+	intNegFixedCount = 32
+	strFixedStart    = 128
+	strFixedCount    = 64
+	listFixedStart   = strFixedStart + strFixedCount
+	listFixedCount   = 64
+)
+
+// Number formats used by rencode's variable-length integers.
+const (
+	decimal   = 10
+	int64Bits = 64
+)
+
+// Encode serializes a value with rencode. Supported types are nil, bools, integers,
+// floats, strings, byte slices, slices, arrays and maps. Map keys are sorted so the
+// output is stable.
+func Encode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := encode(&buf, reflect.ValueOf(value)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, val reflect.Value) error { //nolint:cyclop
+	if !val.IsValid() {
+		buf.WriteByte(chrNone)
+		return nil
+	}
+
+	switch val.Kind() { //nolint:exhaustive
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			buf.WriteByte(chrNone)
+			return nil
+		}
+
+		return encode(buf, val.Elem())
+	case reflect.Bool:
+		if val.Bool() {
+			buf.WriteByte(chrTrue)
+		} else {
+			buf.WriteByte(chrFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encodeInt(buf, val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if num := val.Uint(); num > math.MaxInt64 {
+			buf.WriteByte(chrInt)
+			buf.WriteString(strconv.FormatUint(num, decimal))
+			buf.WriteByte(chrTerm)
+		} else {
+			encodeInt(buf, int64(num))
+		}
+	case reflect.Float32, reflect.Float64:
+		buf.WriteByte(chrFloat64)
+		_ = binary.Write(buf, binary.BigEndian, val.Float())
+	case reflect.String:
+		encodeString(buf, []byte(val.String()))
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 {
+			encodeString(buf, val.Bytes())
+			return nil
+		}
+
+		return encodeList(buf, val)
+	case reflect.Map:
+		return encodeDict(buf, val)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, val.Type())
+	}
+
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, num int64) {
+	switch {
+	case num >= 0 && num < intPosFixedCount:
+		buf.WriteByte(byte(intPosFixedStart + num))
+	case num < 0 && num >= -intNegFixedCount:
+		buf.WriteByte(byte(intNegFixedStart - 1 - num))
+	case num >= math.MinInt8 && num <= math.MaxInt8:
+		buf.WriteByte(chrInt1)
+		buf.WriteByte(byte(int8(num)))
+	case num >= math.MinInt16 && num <= math.MaxInt16:
+		buf.WriteByte(chrInt2)
+		_ = binary.Write(buf, binary.BigEndian, int16(num))
+	case num >= math.MinInt32 && num <= math.MaxInt32:
+		buf.WriteByte(chrInt4)
+		_ = binary.Write(buf, binary.BigEndian, int32(num))
+	default:
+		buf.WriteByte(chrInt8)
+		_ = binary.Write(buf, binary.BigEndian, num)
+	}
+}
+
+func encodeString(buf *bytes.Buffer, data []byte) {
+	if len(data) < strFixedCount {
+		buf.WriteByte(byte(strFixedStart + len(data)))
+	} else {
+		buf.WriteString(strconv.Itoa(len(data)) + ":")
+	}
+
+	buf.Write(data)
+}
+
+func encodeList(buf *bytes.Buffer, val reflect.Value) error {
+	if val.Len() < listFixedCount {
+		buf.WriteByte(byte(listFixedStart + val.Len()))
+	} else {
+		buf.WriteByte(chrList)
+	}
+
+	for idx := 0; idx < val.Len(); idx++ {
+		if err := encode(buf, val.Index(idx)); err != nil {
+			return err
+		}
+	}
+
+	if val.Len() >= listFixedCount {
+		buf.WriteByte(chrTerm)
+	}
+
+	return nil
+}
+
+func encodeDict(buf *bytes.Buffer, val reflect.Value) error {
+	if val.Len() < dictFixedCount {
+		buf.WriteByte(byte(dictFixedStart + val.Len()))
+	} else {
+		buf.WriteByte(chrDict)
+	}
+
+	keys := val.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+
+	for _, key := range keys {
+		if err := encode(buf, key); err != nil {
+			return err
+		}
+
+		if err := encode(buf, val.MapIndex(key)); err != nil {
+			return err
+		}
+	}
+
+	if val.Len() >= dictFixedCount {
+		buf.WriteByte(chrTerm)
+	}
+
+	return nil
+}
+
+// Decode deserializes a rencode value. Lists become []interface{}, strings become string,
+// integers become int64, floats become float64 and dictionaries become map[string]interface{}.
+// Dictionary keys that are not strings are formatted with fmt.Sprint.
+func Decode(data []byte) (interface{}, error) {
+	dec := &decoder{data: data}
+
+	value, err := dec.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if dec.pos != len(data) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidData, len(data)-dec.pos)
+	}
+
+	return value, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) next(size int) ([]byte, error) {
+	if size < 0 || d.pos+size > len(d.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+
+	d.pos += size
+
+	return d.data[d.pos-size : d.pos], nil
+}
+
+func (d *decoder) decode() (interface{}, error) { //nolint:cyclop
+	head, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch code := head[0]; {
+	case code < intPosFixedStart+intPosFixedCount:
+		return int64(code - intPosFixedStart), nil
+	case code >= intNegFixedStart && code < intNegFixedStart+intNegFixedCount:
+		return int64(intNegFixedStart-1) - int64(code), nil
+	case code >= strFixedStart && code < strFixedStart+strFixedCount:
+		data, err := d.next(int(code - strFixedStart))
+		return string(data), err
+	case code >= listFixedStart:
+		return d.decodeList(int(code - listFixedStart))
+	case code >= dictFixedStart && code < dictFixedStart+dictFixedCount:
+		return d.decodeDict(int(code - dictFixedStart))
+	case code >= '0' && code <= '9':
+		return d.decodeString()
+	case code == chrList:
+		return d.decodeList(-1)
+	case code == chrDict:
+		return d.decodeDict(-1)
+	case code == chrTrue, code == chrFalse:
+		return code == chrTrue, nil
+	case code == chrNone:
+		return nil, nil
+	default:
+		return d.decodeNumber(code)
+	}
+}
+
+func (d *decoder) decodeNumber(code byte) (interface{}, error) {
+	sizes := map[byte]int{chrInt1: 1, chrInt2: 2, chrInt4: 4, chrInt8: 8, chrFloat32: 4, chrFloat64: 8}
+
+	if code == chrInt {
+		end := bytes.IndexByte(d.data[d.pos:], chrTerm)
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated integer", ErrInvalidData)
+		}
+
+		text, _ := d.next(end)
+		d.pos++
+
+		if num, err := strconv.ParseInt(string(text), decimal, int64Bits); err == nil {
+			return num, nil
+		}
+
+		num, err := strconv.ParseFloat(string(text), int64Bits)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad integer %q", ErrInvalidData, text)
+		}
+
+		return num, nil
+	}
+
+	size, ok := sizes[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown type code %d", ErrInvalidData, code)
+	}
+
+	data, err := d.next(size)
+	if err != nil {
+		return nil, err
+	}
+
+	switch code {
+	case chrInt1:
+		return int64(int8(data[0])), nil
+	case chrInt2:
+		return int64(int16(binary.BigEndian.Uint16(data))), nil
+	case chrInt4:
+		return int64(int32(binary.BigEndian.Uint32(data))), nil
+	case chrInt8:
+		return int64(binary.BigEndian.Uint64(data)), nil
+	case chrFloat32:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	default:
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	}
+}
+
+func (d *decoder) decodeString() (interface{}, error) {
+	d.pos-- // The first digit of the length was consumed by decode.
+
+	colon := bytes.IndexByte(d.data[d.pos:], ':')
+	if colon < 0 {
+		return nil, fmt.Errorf("%w: unterminated string length", ErrInvalidData)
+	}
+
+	size, err := strconv.Atoi(string(d.data[d.pos : d.pos+colon]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad string length: %v", ErrInvalidData, err)
+	}
+
+	d.pos += colon + 1
+	data, err := d.next(size)
+
+	return string(data), err
+}
+
+// decodeList decodes count items, or items until chrTerm when count is -1.
+func (d *decoder) decodeList(count int) ([]interface{}, error) {
+	list := []interface{}{}
+
+	for idx := 0; count < 0 || idx < count; idx++ {
+		if count < 0 && d.pos < len(d.data) && d.data[d.pos] == chrTerm {
+			d.pos++
+			break
+		}
+
+		item, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, item)
+	}
+
+	return list, nil
+}
+
+// decodeDict decodes count pairs, or pairs until chrTerm when count is -1.
+func (d *decoder) decodeDict(count int) (map[string]interface{}, error) {
+	dict := make(map[string]interface{})
+
+	for idx := 0; count < 0 || idx < count; idx++ {
+		if count < 0 && d.pos < len(d.data) && d.data[d.pos] == chrTerm {
+			d.pos++
+			break
+		}
+
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		if str, ok := key.(string); ok {
+			dict[str] = value
+		} else {
+			dict[fmt.Sprint(key)] = value
+		}
+	}
+
+	return dict, nil
+}
@@ -0,0 +1,140 @@
+package delugerpc_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golift.io/deluge/delugerpc"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	longList := make([]interface{}, 70)
+	for idx := range longList {
+		longList[idx] = int64(idx)
+	}
+
+	longDict := make(map[string]interface{})
+	for idx := 0; idx < 30; idx++ {
+		longDict[fmt.Sprint("key", idx)] = int64(idx)
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "zero", value: 0, want: int64(0)},
+		{name: "fixed positive", value: 43, want: int64(43)},
+		{name: "fixed negative", value: -1, want: int64(-1)},
+		{name: "fixed negative end", value: -32, want: int64(-32)},
+		{name: "int1", value: 44, want: int64(44)},
+		{name: "int1 negative", value: int8(math.MinInt8), want: int64(math.MinInt8)},
+		{name: "int2", value: int16(math.MaxInt16), want: int64(math.MaxInt16)},
+		{name: "int2 negative", value: -200, want: int64(-200)},
+		{name: "int4", value: int32(math.MinInt32), want: int64(math.MinInt32)},
+		{name: "int8", value: int64(math.MaxInt64), want: int64(math.MaxInt64)},
+		{name: "int8 negative", value: int64(math.MinInt64), want: int64(math.MinInt64)},
+		{name: "uint", value: uint16(math.MaxUint16), want: int64(math.MaxUint16)},
+		{name: "big uint", value: uint64(math.MaxUint64), want: float64(math.MaxUint64)},
+		{name: "float64", value: 3.25, want: 3.25},
+		{name: "float32", value: float32(1.5), want: 1.5},
+		{name: "empty string", value: "", want: ""},
+		{name: "short string", value: "deluge", want: "deluge"},
+		{name: "long string", value: strings.Repeat("x", 100), want: strings.Repeat("x", 100)},
+		{name: "bytes", value: []byte("hash"), want: "hash"},
+		{name: "nil", value: nil, want: nil},
+		{name: "nil pointer", value: (*int)(nil), want: nil},
+		{name: "true", value: true, want: true},
+		{name: "false", value: false, want: false},
+		{name: "empty list", value: []string{}, want: []interface{}{}},
+		{name: "short list", value: []interface{}{"a", 1, nil, true}, want: []interface{}{"a", int64(1), nil, true}},
+		{name: "long list", value: longList, want: longList},
+		{name: "array", value: [2]int{1, 2}, want: []interface{}{int64(1), int64(2)}},
+		{name: "empty dict", value: map[string]int{}, want: map[string]interface{}{}},
+		{
+			name:  "short dict",
+			value: map[string]interface{}{"a": 1, "b": []string{"c"}},
+			want:  map[string]interface{}{"a": int64(1), "b": []interface{}{"c"}},
+		},
+		{name: "long dict", value: longDict, want: longDict},
+		{name: "int keys", value: map[int]string{1: "one"}, want: map[string]interface{}{"1": "one"}},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := delugerpc.Encode(test.value)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			got, err := delugerpc.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode(%v): %v", data, err)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeFloat32(t *testing.T) {
+	t.Parallel()
+
+	// Deluge never sends 32-bit floats, but rencode may: code 66 and 1.5 as a big-endian float32.
+	got, err := delugerpc.Decode([]byte{66, 0x3f, 0xc0, 0, 0})
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if got != 1.5 {
+		t.Errorf("got %#v, want 1.5", got)
+	}
+}
+
+func TestEncodeUnsupported(t *testing.T) {
+	t.Parallel()
+
+	if _, err := delugerpc.Encode(make(chan int)); !errors.Is(err, delugerpc.ErrUnsupportedType) {
+		t.Errorf("got %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]byte{
+		"empty":                {},
+		"truncated string":     {130, 'a'},
+		"truncated int2":       {63, 1},
+		"unterminated integer": {61, '1', '2'},
+		"bad integer":          {61, 'x', 127},
+		"unterminated length":  []byte("12"),
+		"unterminated list":    {59, 1},
+		"unknown code":         {47},
+		"trailing bytes":       {1, 2},
+	}
+
+	for name, data := range tests {
+		name, data := name, data
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := delugerpc.Decode(data); !errors.Is(err, delugerpc.ErrInvalidData) {
+				t.Errorf("got %v, want ErrInvalidData", err)
+			}
+		})
+	}
+}
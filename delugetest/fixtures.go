@@ -0,0 +1,50 @@
+package delugetest
+
+import "strings"
+
+// Torrent returns a status fixture for a torrent, shaped like the provided Deluge version.
+// Deluge 2.x fixtures use download_location and move_completed; Deluge 1.x fixtures use
+// save_path and move_on_completed. Change any key in the returned map to suit a test.
+func Torrent(version, hash, name, state string) map[string]interface{} {
+	status := map[string]interface{}{
+		"hash":                  hash,
+		"name":                  name,
+		"state":                 state,
+		"progress":              0.0,
+		"total_size":            1 << 30,
+		"total_done":            0,
+		"download_payload_rate": 0,
+		"upload_payload_rate":   0,
+		"num_seeds":             0,
+		"total_seeds":           0,
+		"num_peers":             0,
+		"total_peers":           0,
+		"eta":                   0,
+		"ratio":                 0.0,
+		"queue":                 -1,
+		"label":                 "",
+		"tracker_host":          "tracker.example.com",
+		"time_added":            1700000000.0,
+		"completed_time":        0.0,
+		"is_finished":           false,
+		"paused":                state == "Paused",
+		"message":               "OK",
+	}
+
+	if strings.HasPrefix(version, "1.") {
+		status["save_path"] = "/downloads"
+		status["move_on_completed"] = false
+		status["move_on_completed_path"] = "/completed"
+
+		return status
+	}
+
+	status["download_location"] = "/downloads"
+	status["save_path"] = "/downloads"
+	status["move_completed"] = false
+	status["move_completed_path"] = "/completed"
+	status["owner"] = "localclient"
+	status["shared"] = false
+
+	return status
+}
@@ -0,0 +1,351 @@
+// Package delugetest provides a fake Deluge web UI for testing code that uses golift.io/deluge.
+// The fake answers the JSON-RPC methods the client uses most, with torrent fixtures
+// shaped like Deluge 1.x or 2.x status payloads. Add or replace methods with Handle.
+package delugetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"golift.io/deluge"
+)
+
+// Versions reported by the fake server.
+const (
+	Version1 = "1.3.15"
+	Version2 = "2.1.1"
+)
+
+// Deluge web UI error codes.
+const (
+	codeNotAuthenticated = 1
+	codeUnknownMethod    = 2
+	codeError            = 3
+)
+
+const (
+	sessionCookie = "_session_id"
+	sessionPrefix = "delugetest"
+	hostID        = "c2bd0eb8d84d4f3ba9a6e4a5f3a2b3c1"
+)
+
+// ErrFake is a generic error a HandlerFunc may return; its message is sent to the client.
+var ErrFake = errors.New("fake deluge error")
+
+// HandlerFunc answers a JSON-RPC method. The returned value is sent as the result.
+// A returned error is sent as a Deluge error, with the error's message.
+type HandlerFunc func(params []json.RawMessage) (interface{}, error)
+
+// Server is a fake Deluge web UI. Create one with NewServer, and Close it when done.
+// Torrents is keyed by hash; each torrent is a map of status keys, like the
+// ones returned by Torrent. Lock the server when changing Torrents while in use.
+type Server struct {
+	*httptest.Server
+	sync.Mutex
+	Password string
+	Version  string
+	Torrents map[string]map[string]interface{}
+	Calls    []string // Every method called, in order.
+	IDs      []int64  // The JSON-RPC id of every call, in order.
+	handlers map[string]HandlerFunc
+	session  int
+}
+
+// NewServer starts a fake Deluge web UI that reports the provided version, like Version1
+// or Version2. It accepts the password "deluge". Torrents starts empty.
+func NewServer(version string) *Server {
+	server := &Server{
+		Password: "deluge",
+		Version:  version,
+		Torrents: make(map[string]map[string]interface{}),
+	}
+	server.handlers = server.defaultHandlers()
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+
+	return server
+}
+
+// Config returns a deluge.Config that points at the server. Version is left empty,
+// so deluge.New detects it like it would from a real server.
+func (s *Server) Config() *deluge.Config {
+	return &deluge.Config{URL: s.URL, Password: s.Password}
+}
+
+// Handle adds or replaces the handler for a method. Return nil from a
+// HandlerFunc to send a null result.
+func (s *Server) Handle(method string, handler HandlerFunc) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Expire ends the current web UI session. The next call that needs one fails with
+// Deluge's "Not authenticated" error, like it would after the web UI session timeout.
+func (s *Server) Expire() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.session++
+}
+
+// AddTorrent adds a torrent fixture, usually from Torrent.
+func (s *Server) AddTorrent(hash string, status map[string]interface{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Torrents[hash] = status
+}
+
+type request struct {
+	ID     int64             `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (s *Server) serveHTTP(resp http.ResponseWriter, req *http.Request) {
+	var rpc request
+	if err := json.NewDecoder(req.Body).Decode(&rpc); err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.Lock()
+	s.Calls = append(s.Calls, rpc.Method)
+	s.IDs = append(s.IDs, rpc.ID)
+	handler, ok := s.handlers[rpc.Method]
+	session := s.sessionValue()
+	s.Unlock()
+
+	reply := map[string]interface{}{"id": rpc.ID, "result": nil, "error": nil}
+
+	cookie, _ := req.Cookie(sessionCookie)
+	authed := cookie != nil && cookie.Value == session
+
+	switch {
+	case rpc.Method == deluge.AuthLogin:
+		reply["result"] = s.login(resp, rpc.Params)
+	case !authed && rpc.Method != deluge.CheckSession:
+		reply["error"] = rpcError{Code: codeNotAuthenticated, Message: "Not authenticated"}
+	case rpc.Method == deluge.CheckSession:
+		reply["result"] = authed
+	case !ok:
+		reply["error"] = rpcError{Code: codeUnknownMethod, Message: "Unknown method"}
+	default:
+		if result, err := handler(rpc.Params); err != nil {
+			reply["error"] = rpcError{Code: codeError, Message: err.Error()}
+		} else {
+			reply["result"] = result
+		}
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(resp).Encode(reply)
+}
+
+// login sets the session cookie when the password matches.
+func (s *Server) login(resp http.ResponseWriter, params []json.RawMessage) bool {
+	var password string
+	if len(params) > 0 {
+		_ = json.Unmarshal(params[0], &password)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if password != s.Password {
+		return false
+	}
+
+	http.SetCookie(resp, &http.Cookie{Name: sessionCookie, Value: s.sessionValue(), Path: "/"})
+
+	return true
+}
+
+// sessionValue is the cookie value of the current session. Lock the server first.
+func (s *Server) sessionValue() string {
+	return fmt.Sprintf("%s%d", sessionPrefix, s.session)
+}
+
+func (s *Server) defaultHandlers() map[string]HandlerFunc {
+	return map[string]HandlerFunc{
+		deluge.GeHosts: func([]json.RawMessage) (interface{}, error) {
+			return [][]interface{}{{hostID, "127.0.0.1", 58846, "localclient"}}, nil
+		},
+		deluge.HostStatus: func([]json.RawMessage) (interface{}, error) {
+			s.Lock()
+			defer s.Unlock()
+
+			if strings.HasPrefix(s.Version, "1.") {
+				return []interface{}{hostID, "127.0.0.1", 58846, "Connected", s.Version}, nil
+			}
+
+			return []interface{}{hostID, "Connected", s.Version}, nil
+		},
+		deluge.WebConnected: func([]json.RawMessage) (interface{}, error) { return true, nil },
+		deluge.GetDaemonInfo: func([]json.RawMessage) (interface{}, error) {
+			s.Lock()
+			defer s.Unlock()
+
+			return s.Version, nil
+		},
+		deluge.GetSession:     s.sessionState,
+		deluge.GetAllTorrents: s.torrentsStatus,
+		deluge.GetTorrentStat: s.torrentStatus,
+	}
+}
+
+func (s *Server) sessionState([]json.RawMessage) (interface{}, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	hashes := make([]string, 0, len(s.Torrents))
+	for hash := range s.Torrents {
+		hashes = append(hashes, hash)
+	}
+
+	sort.Strings(hashes)
+
+	return hashes, nil
+}
+
+// torrentsStatus answers get_torrents_status. It supports id, state and label filters.
+func (s *Server) torrentsStatus(params []json.RawMessage) (interface{}, error) {
+	var (
+		filter map[string]interface{}
+		keys   []string
+	)
+
+	if len(params) > 0 {
+		_ = json.Unmarshal(params[0], &filter) // An empty string means no filter.
+	}
+
+	if len(params) > 1 {
+		_ = json.Unmarshal(params[1], &keys)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	result := make(map[string]map[string]interface{})
+
+	for hash, status := range s.Torrents {
+		if matches(hash, status, filter) {
+			result[hash] = pick(status, keys)
+		}
+	}
+
+	return result, nil
+}
+
+// torrentStatus answers get_torrent_status. Unknown hashes return an empty status, like Deluge.
+func (s *Server) torrentStatus(params []json.RawMessage) (interface{}, error) {
+	var (
+		hash string
+		keys []string
+	)
+
+	if len(params) > 0 {
+		_ = json.Unmarshal(params[0], &hash)
+	}
+
+	if len(params) > 1 {
+		_ = json.Unmarshal(params[1], &keys)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	status, ok := s.Torrents[hash]
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+
+	return pick(status, keys), nil
+}
+
+// matches returns true if a torrent matches every key in a get_torrents_status filter.
+func matches(hash string, status, filter map[string]interface{}) bool {
+	for key, want := range filter {
+		if key == "id" {
+			if !contains(want, hash) {
+				return false
+			}
+
+			continue
+		}
+
+		if key == "state" && want == "Active" {
+			// Deluge's Active filter is not a state; it matches torrents moving data.
+			if rate(status["download_payload_rate"]) <= 0 && rate(status["upload_payload_rate"]) <= 0 {
+				return false
+			}
+
+			continue
+		}
+
+		if have, _ := status[key].(string); !contains(want, have) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rate converts a numeric status value to a float64. Non-numbers are 0.
+func rate(value interface{}) float64 {
+	switch value := value.(type) {
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case float64:
+		return value
+	default:
+		return 0
+	}
+}
+
+// contains compares a filter value, a string or a list of strings, to a status value.
+func contains(want interface{}, have string) bool {
+	switch want := want.(type) {
+	case string:
+		return want == have
+	case []interface{}:
+		for _, item := range want {
+			if item == have {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// pick returns the requested keys from a status, or the whole status when keys is empty.
+func pick(status map[string]interface{}, keys []string) map[string]interface{} {
+	if len(keys) == 0 {
+		return status
+	}
+
+	picked := make(map[string]interface{}, len(keys))
+
+	for _, key := range keys {
+		if value, ok := status[key]; ok {
+			picked[key] = value
+		}
+	}
+
+	return picked
+}
@@ -0,0 +1,68 @@
+package delugetest_test
+
+import (
+	"context"
+	"testing"
+
+	"golift.io/deluge"
+	"golift.io/deluge/delugetest"
+)
+
+const (
+	idleHash   = "1111111111111111111111111111111111111111"
+	activeHash = "2222222222222222222222222222222222222222"
+)
+
+func TestServerFixtures(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []string{delugetest.Version1, delugetest.Version2} {
+		version := version
+
+		t.Run(version, func(t *testing.T) {
+			t.Parallel()
+
+			server := delugetest.NewServer(version)
+			defer server.Close()
+
+			active := delugetest.Torrent(version, activeHash, "active", "Downloading")
+			active["download_payload_rate"] = 1024
+
+			server.AddTorrent(idleHash, delugetest.Torrent(version, idleHash, "idle", "Downloading"))
+			server.AddTorrent(activeHash, active)
+
+			ctx := context.Background()
+
+			client, err := deluge.New(ctx, server.Config())
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			if client.Version != version {
+				t.Errorf("version: got %q, want %q", client.Version, version)
+			}
+
+			xfers, err := client.GetXfersCompatContext(ctx)
+			if err != nil {
+				t.Fatalf("GetXfersCompat: %v", err)
+			}
+
+			if len(xfers) != 2 {
+				t.Fatalf("transfers: got %d, want 2", len(xfers))
+			}
+
+			if got := xfers[idleHash].MoveCompletedLocation(); got != "/completed" {
+				t.Errorf("move completed path: got %q, want /completed", got)
+			}
+
+			xfers, err = client.GetXfersCompatFields(ctx, map[string]interface{}{"state": "Active"}, []string{"name"})
+			if err != nil {
+				t.Fatalf("GetXfersCompatFields(Active): %v", err)
+			}
+
+			if len(xfers) != 1 || xfers[activeHash] == nil {
+				t.Errorf("Active filter: got %d transfers, want only %s", len(xfers), activeHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Deluge event names, for Events. Plugins may send other events.
+const (
+	EventTorrentAdded          = "TorrentAddedEvent"
+	EventTorrentRemoved        = "TorrentRemovedEvent"
+	EventPreTorrentRemoved     = "PreTorrentRemovedEvent"
+	EventTorrentStateChanged   = "TorrentStateChangedEvent"
+	EventTorrentTrackerStatus  = "TorrentTrackerStatusEvent"
+	EventTorrentQueueChanged   = "TorrentQueueChangedEvent"
+	EventTorrentFolderRenamed  = "TorrentFolderRenamedEvent"
+	EventTorrentFileRenamed    = "TorrentFileRenamedEvent"
+	EventTorrentFinished       = "TorrentFinishedEvent"
+	EventTorrentResumed        = "TorrentResumedEvent"
+	EventTorrentFileCompleted  = "TorrentFileCompletedEvent"
+	EventTorrentStorageMoved   = "TorrentStorageMovedEvent"
+	EventCreateTorrentProgress = "CreateTorrentProgressEvent"
+	EventNewVersionAvailable   = "NewVersionAvailableEvent"
+	EventSessionStarted        = "SessionStartedEvent"
+	EventSessionPaused         = "SessionPausedEvent"
+	EventSessionResumed        = "SessionResumedEvent"
+	EventConfigValueChanged    = "ConfigValueChangedEvent"
+	EventPluginEnabled         = "PluginEnabledEvent"
+	EventPluginDisabled        = "PluginDisabledEvent"
+	EventClientDisconnected    = "ClientDisconnectedEvent"
+	EventExternalIP            = "ExternalIPEvent"
+)
+
+// eventDelay is how long Events waits between polls that returned no events, or failed.
+const eventDelay = time.Second
+
+// Event is an event from the Deluge daemon, received with Events.
+// Args holds the event's raw arguments, in order. Data holds the same arguments decoded
+// by Payload, like *TorrentAdded; it is nil for events without arguments, and for events
+// this package does not know. A failed poll, or arguments that do not decode, are sent
+// as an Event with Err set; polling continues after a short wait.
+type Event struct {
+	Name string
+	Args []json.RawMessage
+	Data interface{}
+	Err  error
+}
+
+// Events registers for the named events, and returns a channel that receives them until ctx is canceled.
+// With no names, it registers for torrent added, finished and removed events, and session paused events.
+// Events are long-polled from the web UI with web.get_events; the channel is closed when ctx ends.
+func (d *Deluge) Events(ctx context.Context, names ...string) (<-chan *Event, error) {
+	if len(names) == 0 {
+		names = []string{EventTorrentAdded, EventTorrentFinished, EventTorrentRemoved, EventSessionPaused}
+	}
+
+	for _, name := range names {
+		if _, err := d.Get(ctx, RegisterEvent, []string{name}); err != nil {
+			return nil, fmt.Errorf("get(RegisterEvent): %s: %w", name, err)
+		}
+	}
+
+	events := make(chan *Event)
+
+	go d.pollEvents(ctx, events)
+
+	return events, nil
+}
+
+// pollEvents sends events to the channel until ctx is canceled, then closes it.
+func (d *Deluge) pollEvents(ctx context.Context, events chan<- *Event) {
+	defer close(events)
+
+	for {
+		received, err := d.getEvents(ctx)
+		if err != nil {
+			received = []*Event{{Err: err}}
+		}
+
+		for _, event := range received {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if len(received) > 0 && err == nil {
+			continue
+		}
+
+		if sleep(ctx, eventDelay) != nil {
+			return
+		}
+	}
+}
+
+// getEvents returns the events queued in the web UI since the last call.
+func (d *Deluge) getEvents(ctx context.Context) ([]*Event, error) {
+	response, err := d.Get(ctx, GetEvents, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetEvents): %w", err)
+	}
+
+	var raw [][]json.RawMessage
+	if err := decodeResult(response.Result, &raw); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(events): %w", err)
+	}
+
+	events := make([]*Event, 0, len(raw))
+
+	for _, item := range raw {
+		if len(item) == 0 {
+			continue
+		}
+
+		event := &Event{}
+		if err := json.Unmarshal(item[0], &event.Name); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(name): %w", err)
+		}
+
+		if len(item) > 1 {
+			if err := decodeResult(item[1], &event.Args); err != nil {
+				return nil, fmt.Errorf("json.Unmarshal(args): %w", err)
+			}
+		}
+
+		if event.Data, err = event.Payload(); err != nil && !errors.Is(err, ErrUnknownEvent) {
+			event.Err = err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
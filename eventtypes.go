@@ -0,0 +1,161 @@
+package deluge
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TorrentEvent is the payload for events that only carry a torrent hash:
+// torrent removed, pre-removed, finished and resumed events.
+type TorrentEvent struct {
+	Hash string
+}
+
+// TorrentAdded is the payload for EventTorrentAdded. FromState is true
+// when the torrent was loaded from saved state as the daemon started.
+type TorrentAdded struct {
+	Hash      string
+	FromState bool
+}
+
+// TorrentStateChanged is the payload for EventTorrentStateChanged.
+type TorrentStateChanged struct {
+	Hash  string
+	State string
+}
+
+// TorrentTrackerStatus is the payload for EventTorrentTrackerStatus.
+type TorrentTrackerStatus struct {
+	Hash   string
+	Status string
+}
+
+// TorrentFolderRenamed is the payload for EventTorrentFolderRenamed.
+type TorrentFolderRenamed struct {
+	Hash string
+	Old  string
+	New  string
+}
+
+// TorrentFileRenamed is the payload for EventTorrentFileRenamed.
+type TorrentFileRenamed struct {
+	Hash  string
+	Index int
+	Name  string
+}
+
+// TorrentFileCompleted is the payload for EventTorrentFileCompleted.
+type TorrentFileCompleted struct {
+	Hash  string
+	Index int
+}
+
+// TorrentStorageMoved is the payload for EventTorrentStorageMoved.
+type TorrentStorageMoved struct {
+	Hash string
+	Path string
+}
+
+// CreateTorrentProgress is the payload for EventCreateTorrentProgress.
+type CreateTorrentProgress struct {
+	PieceCount int
+	NumPieces  int
+}
+
+// NewVersionAvailable is the payload for EventNewVersionAvailable.
+type NewVersionAvailable struct {
+	Release string
+}
+
+// ConfigValueChanged is the payload for EventConfigValueChanged. Value is raw because its type depends on Key.
+type ConfigValueChanged struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// PluginEvent is the payload for EventPluginEnabled and EventPluginDisabled.
+type PluginEvent struct {
+	Name string
+}
+
+// ClientDisconnected is the payload for EventClientDisconnected.
+type ClientDisconnected struct {
+	SessionID int
+}
+
+// ExternalIP is the payload for EventExternalIP.
+type ExternalIP struct {
+	IP string
+}
+
+// Payload decodes the event's arguments into the typed payload for its name, like *TorrentAdded.
+// Events without arguments, like session paused and queue changed, return nil.
+// Returns ErrUnknownEvent for events this package does not know, such as plugin events;
+// use Args for those.
+func (e *Event) Payload() (interface{}, error) {
+	var (
+		payload interface{}
+		fields  []interface{}
+	)
+
+	switch e.Name {
+	case EventTorrentRemoved, EventPreTorrentRemoved, EventTorrentFinished, EventTorrentResumed:
+		p := &TorrentEvent{}
+		payload, fields = p, []interface{}{&p.Hash}
+	case EventTorrentAdded:
+		p := &TorrentAdded{}
+		payload, fields = p, []interface{}{&p.Hash, &p.FromState}
+	case EventTorrentStateChanged:
+		p := &TorrentStateChanged{}
+		payload, fields = p, []interface{}{&p.Hash, &p.State}
+	case EventTorrentTrackerStatus:
+		p := &TorrentTrackerStatus{}
+		payload, fields = p, []interface{}{&p.Hash, &p.Status}
+	case EventTorrentFolderRenamed:
+		p := &TorrentFolderRenamed{}
+		payload, fields = p, []interface{}{&p.Hash, &p.Old, &p.New}
+	case EventTorrentFileRenamed:
+		p := &TorrentFileRenamed{}
+		payload, fields = p, []interface{}{&p.Hash, &p.Index, &p.Name}
+	case EventTorrentFileCompleted:
+		p := &TorrentFileCompleted{}
+		payload, fields = p, []interface{}{&p.Hash, &p.Index}
+	case EventTorrentStorageMoved:
+		p := &TorrentStorageMoved{}
+		payload, fields = p, []interface{}{&p.Hash, &p.Path}
+	case EventCreateTorrentProgress:
+		p := &CreateTorrentProgress{}
+		payload, fields = p, []interface{}{&p.PieceCount, &p.NumPieces}
+	case EventNewVersionAvailable:
+		p := &NewVersionAvailable{}
+		payload, fields = p, []interface{}{&p.Release}
+	case EventConfigValueChanged:
+		p := &ConfigValueChanged{}
+		payload, fields = p, []interface{}{&p.Key, &p.Value}
+	case EventPluginEnabled, EventPluginDisabled:
+		p := &PluginEvent{}
+		payload, fields = p, []interface{}{&p.Name}
+	case EventClientDisconnected:
+		p := &ClientDisconnected{}
+		payload, fields = p, []interface{}{&p.SessionID}
+	case EventExternalIP:
+		p := &ExternalIP{}
+		payload, fields = p, []interface{}{&p.IP}
+	case EventTorrentQueueChanged, EventSessionStarted, EventSessionPaused, EventSessionResumed:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, e.Name)
+	}
+
+	for idx, field := range fields {
+		if idx >= len(e.Args) {
+			break // Older daemons send fewer arguments; leave the rest empty.
+		}
+
+		if err := decodeResult(e.Args[idx], field); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(%s[%d]): %w", e.Name, idx, err)
+		}
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,82 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Execute plugin events that run a command.
+const (
+	ExecuteOnComplete = "complete"
+	ExecuteOnAdded    = "added"
+	ExecuteOnRemoved  = "removed"
+)
+
+// ExecuteCommand is a command in the Execute plugin. The command runs on the
+// Deluge server with the torrent's hash, name and download path as arguments.
+type ExecuteCommand struct {
+	ID      string
+	Event   string // ExecuteOnComplete, ExecuteOnAdded or ExecuteOnRemoved.
+	Command string
+}
+
+// GetExecuteCommands returns the Execute plugin's commands.
+// Returns ErrPluginNotInstalled if the Execute plugin is not available.
+func (d *Deluge) GetExecuteCommands(ctx context.Context) ([]ExecuteCommand, error) {
+	response, err := d.Get(ctx, GetCommands, []string{})
+	if err != nil {
+		return nil, pluginError("Execute", fmt.Errorf("get(GetCommands): %w", err))
+	}
+
+	// Each command is a mixed list of [id, event, command].
+	var raw [][]json.RawMessage
+	if err := decodeResult(response.Result, &raw); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(commands): %w", err)
+	}
+
+	const commandSegments = 3
+
+	commands := make([]ExecuteCommand, 0, len(raw))
+
+	for _, item := range raw {
+		if len(item) < commandSegments {
+			continue
+		}
+
+		cmd := ExecuteCommand{}
+		_ = json.Unmarshal(item[0], &cmd.ID)
+		_ = json.Unmarshal(item[1], &cmd.Event)
+		_ = json.Unmarshal(item[2], &cmd.Command)
+		commands = append(commands, cmd)
+	}
+
+	return commands, nil
+}
+
+// AddExecuteCommand adds a command that runs on event, like ExecuteOnComplete.
+func (d *Deluge) AddExecuteCommand(ctx context.Context, event, command string) error {
+	if _, err := d.Get(ctx, AddCommand, []string{event, command}); err != nil {
+		return pluginError("Execute", fmt.Errorf("get(AddCommand): %w", err))
+	}
+
+	return nil
+}
+
+// RemoveExecuteCommand deletes a command by its ID.
+func (d *Deluge) RemoveExecuteCommand(ctx context.Context, id string) error {
+	if _, err := d.Get(ctx, DelCommand, []string{id}); err != nil {
+		return pluginError("Execute", fmt.Errorf("get(DelCommand): %w", err))
+	}
+
+	return nil
+}
+
+// SaveExecuteCommand changes the event and command of an existing command.
+func (d *Deluge) SaveExecuteCommand(ctx context.Context, cmd ExecuteCommand) error {
+	if _, err := d.Get(ctx, SaveCommand, []string{cmd.ID, cmd.Event, cmd.Command}); err != nil {
+		return pluginError("Execute", fmt.Errorf("get(SaveCommand): %w", err))
+	}
+
+	return nil
+}
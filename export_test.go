@@ -0,0 +1,15 @@
+package deluge
+
+import (
+	"context"
+	"time"
+)
+
+// SetSleep replaces the wait between retries, for tests in package deluge_test, and
+// returns a func that restores it. Tests that call it must not run in parallel.
+func SetSleep(fn func(ctx context.Context, dur time.Duration) error) func() {
+	saved := sleep
+	sleep = fn
+
+	return func() { sleep = saved }
+}
@@ -0,0 +1,81 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// File priorities for SetFilePriorities. Deluge 2.x also accepts the values in between.
+const (
+	PrioritySkip   = 0
+	PriorityNormal = 1
+	PriorityHigh   = 7
+)
+
+// SetFilePriorities sets the download priority of every file in a torrent, in file index order.
+// Use PrioritySkip to not download a file. Returns ErrInvalidValue for priorities outside 0-7.
+func (d *Deluge) SetFilePriorities(ctx context.Context, hash string, priorities []int) error {
+	for idx, priority := range priorities {
+		if priority < PrioritySkip || priority > PriorityHigh {
+			return fmt.Errorf("%w: file %d priority %d, use %d-%d", ErrInvalidValue, idx, priority, PrioritySkip, PriorityHigh)
+		}
+	}
+
+	if _, err := d.Get(ctx, SetFilePrios, []interface{}{hash, priorities}); err != nil {
+		return fmt.Errorf("get(SetFilePrios): %w", err)
+	}
+
+	return nil
+}
+
+// SkipFiles stops downloading the files at the provided indexes in a torrent.
+// Other files keep their current priorities. Returns ErrInvalidValue for an index
+// the torrent does not have.
+func (d *Deluge) SkipFiles(ctx context.Context, hash string, indexes ...int) error {
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, []string{"file_priorities"}})
+	if err != nil {
+		return fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	var status struct {
+		FilePriorities []int `json:"file_priorities"`
+	}
+
+	if err := decodeResult(response.Result, &status); err != nil {
+		return fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	for _, idx := range indexes {
+		if idx < 0 || idx >= len(status.FilePriorities) {
+			return fmt.Errorf("%w: %s has no file %d", ErrInvalidValue, hash, idx)
+		}
+
+		status.FilePriorities[idx] = PrioritySkip
+	}
+
+	return d.SetFilePriorities(ctx, hash, status.FilePriorities)
+}
+
+// RenameFile renames or moves a file inside a torrent. newPath is relative to the torrent's
+// download location, and may include folders, like "Show/Season 1/episode.mkv".
+func (d *Deluge) RenameFile(ctx context.Context, hash string, index int, newPath string) error {
+	if _, err := d.Get(ctx, RenameFiles, []interface{}{hash, [][]interface{}{{index, newPath}}}); err != nil {
+		return fmt.Errorf("get(RenameFiles): %w", err)
+	}
+
+	return nil
+}
+
+// RenameFolder renames a folder inside a torrent, like "Old Name" to "New Name".
+// Paths are relative to the torrent's download location; a trailing slash is optional.
+func (d *Deluge) RenameFolder(ctx context.Context, hash, oldFolder, newFolder string) error {
+	oldFolder = strings.TrimSuffix(oldFolder, "/") + "/"
+	newFolder = strings.TrimSuffix(newFolder, "/") + "/"
+
+	if _, err := d.Get(ctx, RenameFolder, []string{hash, oldFolder, newFolder}); err != nil {
+		return fmt.Errorf("get(RenameFolder): %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+)
+
+// Health is a snapshot of the web UI and daemon, built from several requests by Health().
+// Each check is best-effort: a failed check leaves its field empty and records
+// its error in Errors, keyed by web, daemon, free_space, listen_port or states.
+type Health struct {
+	WebConnected    bool             // The web UI is connected to a daemon.
+	DaemonReachable bool             // A backend daemon reports online or connected.
+	Version         string           // Version of the connected (or last) backend daemon.
+	FreeSpace       int64            // Bytes free in the default download location.
+	ListenPortOpen  bool             // The daemon's listen port is reachable from the internet.
+	States          map[string]int   // Number of torrents in each state.
+	Errors          map[string]error // Errors from failed checks.
+}
+
+// Health checks the web UI and daemon and returns the results in one struct.
+// This only returns an error if the context is canceled; failed checks are recorded in Health.Errors.
+// The listen port check contacts an external service and may be slow.
+func (d *Deluge) Health(ctx context.Context) (*Health, error) {
+	health := &Health{States: make(map[string]int), Errors: make(map[string]error)}
+
+	var err error
+	if health.WebConnected, err = d.WebConnected(ctx); err != nil {
+		health.Errors["web"] = err
+	}
+
+	if backends, err := d.Discover(ctx); err != nil {
+		health.Errors["daemon"] = err
+	} else {
+		for _, backend := range backends {
+			if health.Version == "" || backend.Status == "Connected" {
+				health.Version = backend.Version
+			}
+
+			if backend.Status == "Online" || backend.Status == "Connected" {
+				health.DaemonReachable = true
+			}
+		}
+	}
+
+	if health.FreeSpace, err = d.GetFreeSpace(ctx, ""); err != nil {
+		health.Errors["free_space"] = err
+	}
+
+	if health.ListenPortOpen, err = d.TestListenPortOpen(ctx); err != nil {
+		health.Errors["listen_port"] = err
+	}
+
+	if xfers, err := d.GetXfersCompatFields(ctx, nil, []string{"state"}); err != nil {
+		health.Errors["states"] = err
+	} else {
+		for _, xfer := range xfers {
+			health.States[xfer.State]++
+		}
+	}
+
+	return health, ctx.Err()
+}
+
+// WebConnected returns true if the web UI is connected to a backend daemon.
+func (d *Deluge) WebConnected(ctx context.Context) (bool, error) {
+	response, err := d.Get(ctx, WebConnected, []string{})
+	if err != nil {
+		return false, fmt.Errorf("get(WebConnected): %w", err)
+	}
+
+	var connected bool
+	if err := decodeResult(response.Result, &connected); err != nil {
+		return false, fmt.Errorf("json.Unmarshal(connected): %w", err)
+	}
+
+	return connected, nil
+}
+
+// GetFreeSpace returns the free space, in bytes, at a path on the Deluge server.
+// An empty path checks the default download location.
+func (d *Deluge) GetFreeSpace(ctx context.Context, path string) (int64, error) {
+	params := []interface{}{}
+	if path != "" {
+		params = append(params, path)
+	}
+
+	response, err := d.Get(ctx, GetFreeSpace, params)
+	if err != nil {
+		return 0, fmt.Errorf("get(GetFreeSpace): %w", err)
+	}
+
+	var free int64
+	if err := decodeResult(response.Result, &free); err != nil {
+		return 0, fmt.Errorf("json.Unmarshal(free): %w", err)
+	}
+
+	return free, nil
+}
+
+// DaemonInfo holds version and network details for the connected Deluge daemon.
+// Each field is best-effort: a failed request leaves its field empty and records
+// its error in Errors, keyed by version, libtorrent, listen_port or listen_interface.
+type DaemonInfo struct {
+	Version           string
+	LibtorrentVersion string
+	ListenPort        int
+	ListenInterface   string
+	Errors            map[string]error
+}
+
+// DaemonInfo returns version and network details for the connected Deluge daemon.
+// The details are requested one after another. This only returns an error if the
+// context is canceled; failed requests are recorded in DaemonInfo.Errors.
+func (d *Deluge) DaemonInfo(ctx context.Context) (*DaemonInfo, error) {
+	info := &DaemonInfo{Errors: make(map[string]error)}
+
+	var err error
+	if info.Version, err = d.getString(ctx, GetDaemonInfo); err != nil {
+		info.Errors["version"] = err
+	}
+
+	if info.LibtorrentVersion, err = d.getString(ctx, GetLibtorrent); err != nil {
+		info.Errors["libtorrent"] = err
+	}
+
+	if info.ListenPort, err = d.GetListenPort(ctx); err != nil {
+		info.Errors["listen_port"] = err
+	}
+
+	if info.ListenInterface, err = d.GetListenInterface(ctx); err != nil {
+		info.Errors["listen_interface"] = err
+	}
+
+	return info, ctx.Err()
+}
+
+// getString calls a method without parameters that returns a string.
+func (d *Deluge) getString(ctx context.Context, method string) (string, error) {
+	response, err := d.Get(ctx, method, []string{})
+	if err != nil {
+		return "", fmt.Errorf("get(%s): %w", method, err)
+	}
+
+	var str string
+	if err := decodeResult(response.Result, &str); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(%s): %w", method, err)
+	}
+
+	return str, nil
+}
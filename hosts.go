@@ -0,0 +1,91 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// GetHosts returns the backend servers configured in the web UI.
+// The Status and Version of each backend are not filled; use Discover() for those.
+func (d *Deluge) GetHosts(ctx context.Context) ([]Backend, error) {
+	response, err := d.Get(ctx, GeHosts, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	// This method returns a "mixed list" which requires an interface.
+	// Deluge devs apparently hate Go. :(
+	servers := make([][]interface{}, 0)
+	if err := json.Unmarshal(response.Result, &servers); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(rawResult1): %w", err)
+	}
+
+	const hostSegments = 4
+
+	backends := make([]Backend, 0, len(servers))
+
+	for _, server := range servers {
+		if len(server) < hostSegments {
+			continue
+		}
+
+		backend := Backend{}
+		backend.ID, _ = server[0].(string)
+		backend.Addr, _ = server[1].(string)
+		val, _ := server[2].(float64)
+		backend.Addr += ":" + strconv.FormatFloat(val, 'f', 0, 64) //nolint:gomnd,nolintlint
+		backend.Prot, _ = server[3].(string)
+		backends = append(backends, backend)
+	}
+
+	return backends, nil
+}
+
+// GetHostStatus returns the connection status and daemon version of a web UI backend server.
+func (d *Deluge) GetHostStatus(ctx context.Context, id string) (status, version string, err error) {
+	response, err := d.Get(ctx, HostStatus, []string{id})
+	if err != nil {
+		return "", "", err
+	}
+
+	server := make([]interface{}, 0)
+	if err = json.Unmarshal(response.Result, &server); err != nil {
+		return "", "", fmt.Errorf("json.Unmarshal(rawResult2): %w", err)
+	}
+
+	const payloadSegments = 3
+
+	if len(server) < payloadSegments {
+		return "", "", ErrInvalidVersion
+	}
+
+	// Version comes last in the mixed list, and the status comes right before it.
+	var ok bool
+	if version, ok = server[len(server)-1].(string); !ok {
+		return "", "", ErrInvalidVersion
+	}
+
+	status, _ = server[len(server)-2].(string)
+
+	return status, version, nil
+}
+
+// Discover returns every backend server in the web UI with its status and version.
+// Use this to let a user pick a backend.
+func (d *Deluge) Discover(ctx context.Context) ([]Backend, error) {
+	backends, err := d.GetHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range backends {
+		backends[idx].Status, backends[idx].Version, err = d.GetHostStatus(ctx, backends[idx].ID)
+		if err != nil {
+			return nil, fmt.Errorf("host %s: %w", backends[idx].ID, err)
+		}
+	}
+
+	return backends, nil
+}
@@ -0,0 +1,103 @@
+package deluge
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//nolint:gochecknoglobals
+var (
+	compatKeys     map[string]struct{}
+	compatKeysOnce sync.Once
+	extraKeys      []string
+	extraKeysLock  sync.RWMutex
+)
+
+// RegisterStatusKey adds a status key, usually one provided by a plugin, to the keys requested
+// by GetXfersCompat and GetXfersCompatFields. Raw values for registered keys are stored in
+// XferStatusCompat.Extra, and in XferStatus.Extra by GetXfers, which requests every key.
+// Registering a key twice does nothing. Safe for concurrent use.
+func RegisterStatusKey(name string) {
+	extraKeysLock.Lock()
+	defer extraKeysLock.Unlock()
+
+	for _, key := range extraKeys {
+		if key == name {
+			return
+		}
+	}
+
+	extraKeys = append(extraKeys, name)
+}
+
+// registeredKeys returns a copy of the keys added with RegisterStatusKey.
+func registeredKeys() []string {
+	extraKeysLock.RLock()
+	defer extraKeysLock.RUnlock()
+
+	return append([]string{}, extraKeys...)
+}
+
+// ValidateKeys returns the provided status keys that are not known XferStatusCompat fields.
+// Deluge silently ignores unknown keys, so use this to catch typos in key lists. Keys
+// added by plugins are valid in Deluge but not known here; they are returned too,
+// unless they were registered with RegisterStatusKey.
+func ValidateKeys(keys []string) []string {
+	compatKeysOnce.Do(func() {
+		compatKeys = make(map[string]struct{})
+		for _, key := range jsonKeys(reflect.TypeOf(XferStatusCompat{})) {
+			compatKeys[key] = struct{}{}
+		}
+	})
+
+	registered := make(map[string]struct{})
+	for _, key := range registeredKeys() {
+		registered[key] = struct{}{}
+	}
+
+	unknown := []string{}
+
+	for _, key := range keys {
+		_, known := compatKeys[key]
+		if _, ok := registered[key]; !known && !ok {
+			unknown = append(unknown, key)
+		}
+	}
+
+	return unknown
+}
+
+// jsonKeys returns the json tag names for the fields in a struct type.
+func jsonKeys(structType reflect.Type) []string {
+	keys := []string{}
+
+	for idx := 0; idx < structType.NumField(); idx++ {
+		name := strings.Split(structType.Field(idx).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+
+	return keys
+}
+
+// StatusKeys returns the status keys named by the json tags on a struct, a pointer to a struct,
+// or a map or slice of those, such as map[string]*MyStatus. Pass the result as the keys
+// for GetXfersCompatFields, or use GetXfersFields to do both at once.
+func StatusKeys(v interface{}) []string {
+	valType := reflect.TypeOf(v)
+
+	for valType != nil {
+		switch valType.Kind() { //nolint:exhaustive
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
+			valType = valType.Elem()
+		case reflect.Struct:
+			return jsonKeys(valType)
+		default:
+			return []string{}
+		}
+	}
+
+	return []string{}
+}
@@ -0,0 +1,252 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+)
+
+// LabelOptions are the settings for a single label in the Label plugin.
+type LabelOptions struct {
+	ApplyMax            bool     `json:"apply_max"`
+	MaxDownloadSpeed    float64  `json:"max_download_speed"`
+	MaxUploadSpeed      float64  `json:"max_upload_speed"`
+	MaxConnections      int64    `json:"max_connections"`
+	MaxUploadSlots      int64    `json:"max_upload_slots"`
+	PrioritizeFirstLast bool     `json:"prioritize_first_last"`
+	ApplyQueue          bool     `json:"apply_queue"`
+	IsAutoManaged       bool     `json:"is_auto_managed"`
+	StopAtRatio         bool     `json:"stop_at_ratio"`
+	StopRatio           float64  `json:"stop_ratio"`
+	RemoveAtRatio       bool     `json:"remove_at_ratio"`
+	ApplyMoveCompleted  bool     `json:"apply_move_completed"`
+	MoveCompleted       bool     `json:"move_completed"`
+	MoveCompletedPath   string   `json:"move_completed_path"`
+	AutoAdd             bool     `json:"auto_add"`
+	AutoAddTrackers     []string `json:"auto_add_trackers"`
+}
+
+// GetLabelOptions returns the Label plugin settings for a label.
+func (d *Deluge) GetLabelOptions(ctx context.Context, label string) (*LabelOptions, error) {
+	response, err := d.Get(ctx, GetLabelOpts, []string{label})
+	if err != nil {
+		return nil, pluginError("Label", fmt.Errorf("get(GetLabelOpts): %w", err))
+	}
+
+	var options LabelOptions
+	if err := decodeResult(response.Result, &options); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(options): %w", err)
+	}
+
+	return &options, nil
+}
+
+// SetLabelOptions saves the Label plugin settings for a label, and the plugin applies them
+// to every torrent that has the label right away. A false Apply* field turns that group of
+// settings off for the label, so modify the value from GetLabelOptions instead of a new one.
+func (d *Deluge) SetLabelOptions(ctx context.Context, label string, options *LabelOptions) error {
+	if _, err := d.Get(ctx, SetLabelOpts, []interface{}{label, options}); err != nil {
+		return pluginError("Label", fmt.Errorf("get(SetLabelOpts): %w", err))
+	}
+
+	return nil
+}
+
+// MoveStorageToLabelPath moves torrents into the move completed path configured on a label.
+// Returns ErrUnknownLabel if the label has no move completed path.
+func (d *Deluge) MoveStorageToLabelPath(ctx context.Context, hashes []string, label string) error {
+	options, err := d.GetLabelOptions(ctx, label)
+	if err != nil {
+		return err
+	}
+
+	if options.MoveCompletedPath == "" {
+		return fmt.Errorf("%w: %s has no move completed path", ErrUnknownLabel, label)
+	}
+
+	return d.MoveStorage(ctx, hashes, options.MoveCompletedPath)
+}
+
+// GetLabels returns every label in the Label plugin.
+func (d *Deluge) GetLabels(ctx context.Context) ([]string, error) {
+	response, err := d.Get(ctx, GetLabels, []string{})
+	if err != nil {
+		return nil, pluginError("Label", fmt.Errorf("get(GetLabels): %w", err))
+	}
+
+	labels := []string{}
+	if err := decodeResult(response.Result, &labels); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(labels): %w", err)
+	}
+
+	return labels, nil
+}
+
+// AddLabel creates a new label in the Label plugin. Labels must be lowercase.
+func (d *Deluge) AddLabel(ctx context.Context, label string) error {
+	if _, err := d.Get(ctx, AddLabel, []string{label}); err != nil {
+		return pluginError("Label", fmt.Errorf("get(AddLabel): %w", err))
+	}
+
+	return nil
+}
+
+// ensureLabel makes sure a label exists, creating it if Config.AutoCreateLabels is set.
+// The empty label means "no label" and always exists.
+func (d *Deluge) ensureLabel(ctx context.Context, label string) error {
+	if label == "" {
+		return nil
+	}
+
+	labels, err := d.GetLabels(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range labels {
+		if existing == label {
+			return nil
+		}
+	}
+
+	if !d.addLabel {
+		return fmt.Errorf("%w: %s", ErrUnknownLabel, label)
+	}
+
+	return d.AddLabel(ctx, label)
+}
+
+// SetTorrentLabel applies a label to a torrent; an empty label removes the torrent's label.
+// Deluge only applies labels that already exist. A missing label returns ErrUnknownLabel,
+// unless Config.AutoCreateLabels is set, which creates it with label.add first.
+func (d *Deluge) SetTorrentLabel(ctx context.Context, hash, label string) error {
+	if err := d.ensureLabel(ctx, label); err != nil {
+		return err
+	}
+
+	return d.setLabel(ctx, hash, label)
+}
+
+// setLabel applies a label that is known to exist to a torrent.
+func (d *Deluge) setLabel(ctx context.Context, hash, label string) error {
+	if _, err := d.Get(ctx, SetLabel, []string{hash, label}); err != nil {
+		return pluginError("Label", fmt.Errorf("get(SetLabel): %s: %w", hash, err))
+	}
+
+	return nil
+}
+
+// RelabelTorrents moves every torrent with fromLabel to toLabel, and returns how many changed.
+// An empty label means "no label" on either side. If toLabel does not exist, this returns
+// ErrUnknownLabel, or creates the label if Config.AutoCreateLabels is set.
+func (d *Deluge) RelabelTorrents(ctx context.Context, fromLabel, toLabel string) (int, error) {
+	if fromLabel == toLabel {
+		return 0, nil
+	}
+
+	if err := d.ensureLabel(ctx, toLabel); err != nil {
+		return 0, err
+	}
+
+	xfers, err := d.GetXfersCompatFields(ctx, map[string]interface{}{"label": fromLabel}, []string{"label"})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for hash := range xfers {
+		if err := d.setLabel(ctx, hash, toLabel); err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+// SetTorrentLabels applies a label to each torrent, from a map of hash to label.
+// Failures are returned per hash, and do not stop the rest of the batch; a label that
+// does not exist fails with ErrUnknownLabel, unless Config.AutoCreateLabels is set.
+// The error is only non-nil if the existing labels could not be retrieved.
+func (d *Deluge) SetTorrentLabels(ctx context.Context, labels map[string]string) (map[string]error, error) {
+	existing, err := d.GetLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]error{"": nil} // The empty label removes a torrent's label.
+	for _, label := range existing {
+		known[label] = nil
+	}
+
+	failed := make(map[string]error)
+
+	for hash, label := range labels {
+		labelErr, ok := known[label]
+		if !ok {
+			labelErr = fmt.Errorf("%w: %s", ErrUnknownLabel, label)
+			if d.addLabel {
+				labelErr = d.AddLabel(ctx, label)
+			}
+
+			known[label] = labelErr
+		}
+
+		if labelErr != nil {
+			failed[hash] = labelErr
+			continue
+		}
+
+		if err := d.setLabel(ctx, hash, label); err != nil {
+			failed[hash] = err
+		}
+	}
+
+	return failed, nil
+}
+
+// PauseLabel pauses every torrent with a label, and returns how many were paused.
+// An empty label pauses torrents without a label.
+func (d *Deluge) PauseLabel(ctx context.Context, label string) (int, error) {
+	hashes, err := d.labelHashes(ctx, label)
+	if err != nil || len(hashes) == 0 {
+		return 0, err
+	}
+
+	if err := d.PauseTorrents(ctx, hashes); err != nil {
+		return 0, err
+	}
+
+	return len(hashes), nil
+}
+
+// ResumeLabel resumes every torrent with a label, and returns how many were resumed.
+// An empty label resumes torrents without a label.
+func (d *Deluge) ResumeLabel(ctx context.Context, label string) (int, error) {
+	hashes, err := d.labelHashes(ctx, label)
+	if err != nil || len(hashes) == 0 {
+		return 0, err
+	}
+
+	if err := d.ResumeTorrents(ctx, hashes); err != nil {
+		return 0, err
+	}
+
+	return len(hashes), nil
+}
+
+// labelHashes returns the hashes of the torrents with a label; an empty label means "no label".
+func (d *Deluge) labelHashes(ctx context.Context, label string) ([]string, error) {
+	xfers, err := d.GetXfersCompatFields(ctx, map[string]interface{}{"label": label}, []string{"label"})
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(xfers))
+	for hash := range xfers {
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
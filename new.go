@@ -0,0 +1,81 @@
+package deluge
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Option changes how NewWithOptions creates a client.
+type Option func(*options)
+
+// options are the settings collected from Option functions.
+type options struct {
+	config  Config
+	timeout time.Duration
+	logger  Logger
+	tls     *tls.Config
+}
+
+// Logger receives debug messages about retries and re-logins. *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// NewWithOptions creates a client for the web UI at url, logs in with password, and applies options.
+// It works like New, but new settings can be added as options without changing Config.
+func NewWithOptions(ctx context.Context, url, password string, opts ...Option) (*Deluge, error) {
+	settings := &options{config: Config{URL: url, Password: password}}
+	for _, opt := range opts {
+		opt(settings)
+	}
+
+	if settings.tls != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+		transport.TLSClientConfig = settings.tls
+		settings.config.Client = &http.Client{Transport: transport}
+	}
+
+	deluge, err := newConfig(ctx, &settings.config, false)
+	if err != nil {
+		return nil, err
+	}
+
+	deluge.timeout = settings.timeout
+	deluge.log = settings.logger
+
+	return deluge, deluge.start(ctx, &settings.config)
+}
+
+// WithHTTPAuth sets the username and password for a web UI behind HTTP basic auth.
+func WithHTTPAuth(username, password string) Option {
+	return func(o *options) {
+		o.config.HTTPUser = username
+		o.config.HTTPPass = password
+	}
+}
+
+// WithTimeout applies a timeout to each request whose context has no deadline.
+// This is the same as calling WithTimeout on the returned client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithLogger sends debug messages about retries and re-logins to logger.
+func WithLogger(logger Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithTLSConfig sets the TLS config used to connect to an https web UI,
+// for example to trust a self-signed certificate.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) { o.tls = config }
+}
+
+// logf sends a debug message to the logger, if there is one.
+func (d *Deluge) logf(format string, v ...interface{}) {
+	if d.log != nil {
+		d.log.Printf(format, v...)
+	}
+}
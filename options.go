@@ -0,0 +1,279 @@
+package deluge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// torrentOption is a single per-torrent option. Deluge 2.x sets the key with
+// set_torrent_options. Deluge 1.x uses a setter method per option, if it has one.
+type torrentOption struct {
+	key      string
+	value    interface{}
+	v1Method string
+}
+
+// setTorrentOptions sets options on torrents, in one request on Deluge 2.x.
+// Returns ErrUnsupportedVersion on Deluge 1.x if any option has no 1.x setter.
+func (d *Deluge) setTorrentOptions(ctx context.Context, hashes []string, options ...torrentOption) error {
+	if !d.isV1() {
+		opts := make(map[string]interface{}, len(options))
+		for _, opt := range options {
+			opts[opt.key] = opt.value
+		}
+
+		if _, err := d.Get(ctx, SetTorrentOpts, []interface{}{hashes, opts}); err != nil {
+			return fmt.Errorf("get(SetTorrentOpts): %w", err)
+		}
+
+		return nil
+	}
+
+	for _, opt := range options {
+		if opt.v1Method == "" {
+			return fmt.Errorf("%w: %s requires Deluge 2", ErrUnsupportedVersion, opt.key)
+		}
+	}
+
+	for _, opt := range options {
+		for _, hash := range hashes {
+			if _, err := d.Get(ctx, opt.v1Method, []interface{}{hash, opt.value}); err != nil {
+				return fmt.Errorf("get(%s): %w", opt.v1Method, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetSequentialDownload turns sequential downloading on or off for torrents.
+// Returns ErrUnsupportedVersion on Deluge 1.x, which lacks this feature.
+func (d *Deluge) SetSequentialDownload(ctx context.Context, hashes []string, enabled bool) error {
+	return d.setTorrentOptions(ctx, hashes, torrentOption{key: "sequential_download", value: enabled})
+}
+
+// SetFirstLastPriority turns first and last piece priority on or off for torrents.
+func (d *Deluge) SetFirstLastPriority(ctx context.Context, hashes []string, enabled bool) error {
+	return d.setTorrentOptions(ctx, hashes,
+		torrentOption{key: "prioritize_first_last_pieces", value: enabled, v1Method: SetFirstLast})
+}
+
+// SetTorrentOwner assigns torrents to a different Deluge user. This requires Deluge 2.x
+// and a daemon account with admin access; the web UI uses its daemon login for this.
+// Returns ErrUnsupportedVersion on Deluge 1.x, ErrPermissionDenied if the daemon refuses,
+// and ErrMethodNotFound if the daemon does not know set_torrent_options.
+func (d *Deluge) SetTorrentOwner(ctx context.Context, hashes []string, owner string) error {
+	err := d.setTorrentOptions(ctx, hashes, torrentOption{key: "owner", value: owner})
+	if isPermissionError(err) {
+		return fmt.Errorf("%w: %v", ErrPermissionDenied, err)
+	}
+
+	return err
+}
+
+// isPermissionError returns true if Deluge refused a request because the daemon login lacks access.
+func isPermissionError(err error) bool {
+	if err == nil || errors.Is(err, ErrMethodNotFound) {
+		return false
+	}
+
+	msg := err.Error()
+
+	return strings.Contains(msg, "NotAuthorizedError") || strings.Contains(msg, "Auth level too low")
+}
+
+// RenameTorrent changes a torrent's display name. Deluge 2.x sets the name directly, and
+// falls back to renaming the torrent's top folder if the daemon refuses the name option.
+// Deluge 1.x has no name option, so the torrent's top folder is renamed instead.
+// Returns ErrCannotRename if the name cannot be set and the files are not in a folder.
+func (d *Deluge) RenameTorrent(ctx context.Context, hash, newName string) error {
+	if d.isV1() {
+		return d.renameRoot(ctx, hash, newName)
+	}
+
+	err := d.setTorrentOptions(ctx, []string{hash}, torrentOption{key: "name", value: newName})
+	if err == nil {
+		return nil
+	}
+
+	if folderErr := d.renameRoot(ctx, hash, newName); folderErr != nil {
+		return fmt.Errorf("%w: %s: setting name: %v, renaming folder: %v", ErrCannotRename, hash, err, folderErr)
+	}
+
+	return nil
+}
+
+// renameRoot renames a torrent's top folder. Returns ErrCannotRename if its files are not in a folder.
+func (d *Deluge) renameRoot(ctx context.Context, hash, newName string) error {
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, []string{"files"}})
+	if err != nil {
+		return fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	var status struct {
+		Files []struct {
+			Path string `json:"path"`
+		} `json:"files"`
+	}
+
+	if err := decodeResult(response.Result, &status); err != nil {
+		return fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	if len(status.Files) == 0 || !strings.Contains(status.Files[0].Path, "/") {
+		return fmt.Errorf("%w: %s has no top folder", ErrCannotRename, hash)
+	}
+
+	root := status.Files[0].Path[:strings.Index(status.Files[0].Path, "/")]
+
+	return d.RenameFolder(ctx, hash, root, newName)
+}
+
+// SetShared marks torrents as shared (or not) on multi-user Deluge 2.x.
+// A shared torrent is visible to every Deluge user, not only its owner.
+// Returns ErrUnsupportedVersion on Deluge 1.x, which has no torrent owners.
+func (d *Deluge) SetShared(ctx context.Context, hashes []string, shared bool) error {
+	return d.setTorrentOptions(ctx, hashes, torrentOption{key: "shared", value: shared})
+}
+
+// SetMaxUploadSlots sets the upload slot limit for a torrent. Use -1 for unlimited.
+func (d *Deluge) SetMaxUploadSlots(ctx context.Context, hash string, slots int) error {
+	return d.SetTorrentsMaxUploadSlots(ctx, []string{hash}, slots)
+}
+
+// SetTorrentsMaxUploadSlots sets the upload slot limit for torrents. Use -1 for unlimited.
+// Returns ErrInvalidValue for values below -1.
+func (d *Deluge) SetTorrentsMaxUploadSlots(ctx context.Context, hashes []string, slots int) error {
+	if slots < -1 {
+		return fmt.Errorf("%w: max upload slots %d, use -1 for unlimited", ErrInvalidValue, slots)
+	}
+
+	return d.setTorrentOptions(ctx, hashes, torrentOption{key: "max_upload_slots", value: slots, v1Method: SetMaxSlots})
+}
+
+// SetMaxConnections sets the connection limit for a torrent. Use -1 for unlimited.
+func (d *Deluge) SetMaxConnections(ctx context.Context, hash string, conns int) error {
+	return d.SetTorrentsMaxConnections(ctx, []string{hash}, conns)
+}
+
+// SetTorrentsMaxConnections sets the connection limit for torrents. Use -1 for unlimited.
+// Returns ErrInvalidValue for values below -1.
+func (d *Deluge) SetTorrentsMaxConnections(ctx context.Context, hashes []string, conns int) error {
+	if conns < -1 {
+		return fmt.Errorf("%w: max connections %d, use -1 for unlimited", ErrInvalidValue, conns)
+	}
+
+	return d.setTorrentOptions(ctx, hashes, torrentOption{key: "max_connections", value: conns, v1Method: SetMaxConns})
+}
+
+// resetOptions maps per-torrent option keys to the daemon config keys that hold their defaults.
+//
+//nolint:gochecknoglobals
+var resetOptions = []struct {
+	config string
+	torrentOption
+}{
+	{"max_download_speed_per_torrent", torrentOption{key: "max_download_speed", v1Method: SetMaxDown}},
+	{"max_upload_speed_per_torrent", torrentOption{key: "max_upload_speed", v1Method: SetMaxUp}},
+	{"max_connections_per_torrent", torrentOption{key: "max_connections", v1Method: SetMaxConns}},
+	{"max_upload_slots_per_torrent", torrentOption{key: "max_upload_slots", v1Method: SetMaxSlots}},
+	{"stop_seed_at_ratio", torrentOption{key: "stop_at_ratio", v1Method: SetStopAtRatio}},
+	{"stop_seed_ratio", torrentOption{key: "stop_ratio", v1Method: SetStopRatio}},
+	{"remove_seed_at_ratio", torrentOption{key: "remove_at_ratio", v1Method: SetRemoveRatio}},
+}
+
+// ResetTorrentOptions clears per-torrent overrides by copying the daemon's defaults onto torrents.
+// Deluge has no "inherit" value; a torrent copies the defaults when it is added, so they are read
+// from the daemon config and set again. These torrent options are reset from these config keys:
+//   - max_download_speed and max_upload_speed: max_download_speed_per_torrent, max_upload_speed_per_torrent.
+//   - max_connections and max_upload_slots: max_connections_per_torrent, max_upload_slots_per_torrent.
+//   - stop_at_ratio, stop_ratio and remove_at_ratio: stop_seed_at_ratio, stop_seed_ratio, remove_seed_at_ratio.
+//
+// Limits from the Label plugin are not restored; set the torrent's label again to apply them.
+// Deluge 2.x resets all options in one request, Deluge 1.x uses a request per option and torrent.
+func (d *Deluge) ResetTorrentOptions(ctx context.Context, hashes []string) error {
+	keys := make([]string, len(resetOptions))
+	for idx, opt := range resetOptions {
+		keys[idx] = opt.config
+	}
+
+	defaults, err := d.GetConfigValues(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	options := make([]torrentOption, len(resetOptions))
+
+	for idx, opt := range resetOptions {
+		value, ok := defaults[opt.config]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrConfigKeyNotFound, opt.config)
+		}
+
+		options[idx] = opt.torrentOption
+		options[idx].value = value
+	}
+
+	return d.setTorrentOptions(ctx, hashes, options...)
+}
+
+// TorrentOptions are per-torrent settings for SetTorrentOptions. Nil fields are not changed.
+// Speeds are in KiB/s; use -1 for unlimited speeds, connections and slots.
+// SuperSeeding requires Deluge 2.x.
+type TorrentOptions struct {
+	MaxDownloadSpeed  *float64
+	MaxUploadSpeed    *float64
+	MaxConnections    *int
+	MaxUploadSlots    *int
+	StopAtRatio       *bool
+	StopRatio         *float64
+	RemoveAtRatio     *bool
+	AutoManaged       *bool
+	MoveCompleted     *bool
+	MoveCompletedPath *string
+	SuperSeeding      *bool
+}
+
+// options returns the non-nil settings as torrent options.
+func (o *TorrentOptions) options() []torrentOption {
+	all := []struct {
+		set bool
+		torrentOption
+	}{
+		{o.MaxDownloadSpeed != nil, torrentOption{"max_download_speed", o.MaxDownloadSpeed, SetMaxDown}},
+		{o.MaxUploadSpeed != nil, torrentOption{"max_upload_speed", o.MaxUploadSpeed, SetMaxUp}},
+		{o.MaxConnections != nil, torrentOption{"max_connections", o.MaxConnections, SetMaxConns}},
+		{o.MaxUploadSlots != nil, torrentOption{"max_upload_slots", o.MaxUploadSlots, SetMaxSlots}},
+		{o.StopAtRatio != nil, torrentOption{"stop_at_ratio", o.StopAtRatio, SetStopAtRatio}},
+		{o.StopRatio != nil, torrentOption{"stop_ratio", o.StopRatio, SetStopRatio}},
+		{o.RemoveAtRatio != nil, torrentOption{"remove_at_ratio", o.RemoveAtRatio, SetRemoveRatio}},
+		{o.AutoManaged != nil, torrentOption{"auto_managed", o.AutoManaged, SetAutoManaged}},
+		{o.MoveCompleted != nil, torrentOption{"move_completed", o.MoveCompleted, SetMoveDone}},
+		{o.MoveCompletedPath != nil, torrentOption{"move_completed_path", o.MoveCompletedPath, SetMovePath}},
+		{o.SuperSeeding != nil, torrentOption{"super_seeding", o.SuperSeeding, ""}},
+	}
+
+	options := []torrentOption{}
+
+	for _, opt := range all {
+		if opt.set {
+			options = append(options, opt.torrentOption)
+		}
+	}
+
+	return options
+}
+
+// SetTorrentOptions changes the non-nil settings in opts on a torrent.
+// Deluge 2.x sets them in one request; Deluge 1.x uses one request per setting.
+// Returns ErrUnsupportedVersion on Deluge 1.x if SuperSeeding is set.
+func (d *Deluge) SetTorrentOptions(ctx context.Context, hash string, opts TorrentOptions) error {
+	options := opts.options()
+	if len(options) == 0 {
+		return nil
+	}
+
+	return d.setTorrentOptions(ctx, []string{hash}, options...)
+}
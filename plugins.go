@@ -0,0 +1,86 @@
+package deluge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GetInstalledPlugins returns every plugin installed on the Deluge daemon, enabled or not.
+func (d *Deluge) GetInstalledPlugins(ctx context.Context) ([]string, error) {
+	response, err := d.Get(ctx, GetPlugins, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetPlugins): %w", err)
+	}
+
+	plugins := []string{}
+	if err := decodeResult(response.Result, &plugins); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(plugins): %w", err)
+	}
+
+	return plugins, nil
+}
+
+// GetEnabledPlugins returns the plugins currently enabled on the Deluge daemon.
+func (d *Deluge) GetEnabledPlugins(ctx context.Context) ([]string, error) {
+	response, err := d.Get(ctx, GetEnabled, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetEnabled): %w", err)
+	}
+
+	plugins := []string{}
+	if err := decodeResult(response.Result, &plugins); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(plugins): %w", err)
+	}
+
+	return plugins, nil
+}
+
+// EnablePlugin enables an installed plugin on the Deluge daemon.
+func (d *Deluge) EnablePlugin(ctx context.Context, name string) error {
+	if _, err := d.Get(ctx, EnablePlugin, []string{name}); err != nil {
+		return fmt.Errorf("get(EnablePlugin): %w", err)
+	}
+
+	return nil
+}
+
+// EnsurePlugin makes sure a plugin is installed and enabled. Plugin names are not case sensitive.
+// Enables the plugin if it's installed but disabled, and does nothing if it's already enabled.
+// Returns ErrPluginNotInstalled if the plugin is not installed on the Deluge daemon.
+func (d *Deluge) EnsurePlugin(ctx context.Context, name string) error {
+	enabled, err := d.GetEnabledPlugins(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range enabled {
+		if strings.EqualFold(plugin, name) {
+			return nil
+		}
+	}
+
+	installed, err := d.GetInstalledPlugins(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range installed {
+		if strings.EqualFold(plugin, name) {
+			return d.EnablePlugin(ctx, plugin)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrPluginNotInstalled, name)
+}
+
+// pluginError turns a missing-method error into ErrPluginNotInstalled for the named plugin.
+// Deluge reports plugin methods as unknown when the plugin is not installed or not enabled.
+func pluginError(plugin string, err error) error {
+	if errors.Is(err, ErrMethodNotFound) {
+		return fmt.Errorf("%w: %s: %v", ErrPluginNotInstalled, plugin, err)
+	}
+
+	return err
+}
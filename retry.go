@@ -0,0 +1,94 @@
+package deluge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// retryDelay is how long to wait before the first retry. Each retry waits one retryDelay longer.
+const retryDelay = 500 * time.Millisecond
+
+// do sends a request, and retries it up to d.retries times when it is retryable.
+func (d *Deluge) do(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := d.client.Do(req)
+		if attempt > d.retries || req.Context().Err() != nil || !d.retryable(resp, err) {
+			return resp, err //nolint:wrapcheck
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDiscardBytes))
+			resp.Body.Close()
+		}
+
+		d.logf("deluge: retrying request, attempt %d of %d: %v", attempt, d.retries, retryReason(resp, err))
+
+		if err := sleep(req.Context(), time.Duration(attempt)*retryDelay); err != nil {
+			return nil, err
+		}
+
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable uses the RetryPredicate from Config, or retries transport errors and 5xx responses.
+func (d *Deluge) retryable(resp *http.Response, err error) bool {
+	if d.retryIf != nil {
+		return d.retryIf(resp, err)
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// retryReason describes why a request is being retried, for the logger.
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return resp.Status
+}
+
+// rewind returns a copy of a sent request with a fresh body, so it can be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody == nil {
+		return retry, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewinding request body: %w", err)
+	}
+
+	retry.Body = body
+
+	return retry, nil
+}
+
+// sleep waits for the duration, or until the context is canceled. Every wait in the retry
+// path goes through this variable so tests can replace it and advance time without really
+// waiting. This is an internal seam, not public API.
+//
+//nolint:gochecknoglobals
+var sleep = func(ctx context.Context, dur time.Duration) error {
+	timer := time.NewTimer(dur)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,244 @@
+package deluge_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golift.io/deluge"
+	"golift.io/deluge/delugetest"
+)
+
+// flaky wraps a fake server so that, once failing is set, its next responses fail.
+type flaky struct {
+	sync.Mutex
+	failing  int // How many more requests fail.
+	status   int // The status to fail with.
+	requests int // Requests seen since the failures were set.
+}
+
+func newFlaky(server *delugetest.Server) *flaky {
+	wrapper := &flaky{}
+	handler := server.Server.Config.Handler
+	server.Server.Config.Handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		wrapper.Lock()
+		wrapper.requests++
+		status := 0
+
+		if wrapper.failing > 0 {
+			wrapper.failing--
+			status = wrapper.status
+		}
+
+		wrapper.Unlock()
+
+		if status != 0 {
+			http.Error(resp, http.StatusText(status), status)
+			return
+		}
+
+		handler.ServeHTTP(resp, req)
+	})
+
+	return wrapper
+}
+
+// fail makes the next count requests respond with status.
+func (f *flaky) fail(count, status int) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.failing, f.status, f.requests = count, status, 0
+}
+
+func (f *flaky) seen() int {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.requests
+}
+
+// recordSleep replaces the retry wait with one that returns at once, and records each duration.
+func recordSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+
+	var waits []time.Duration
+
+	t.Cleanup(deluge.SetSleep(func(ctx context.Context, dur time.Duration) error {
+		waits = append(waits, dur)
+		return ctx.Err()
+	}))
+
+	return &waits
+}
+
+// retryClient returns a client for a fake server that retries up to maxRetries times.
+func retryClient(t *testing.T, maxRetries int) (*deluge.Deluge, *delugetest.Server, *flaky) {
+	t.Helper()
+
+	server := delugetest.NewServer(delugetest.Version2)
+	t.Cleanup(server.Close)
+
+	flaky := newFlaky(server)
+	config := server.Config()
+	config.MaxRetries = maxRetries
+
+	client, err := deluge.New(context.Background(), config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	return client, server, flaky
+}
+
+func TestRetryBackoff(t *testing.T) {
+	waits := recordSleep(t)
+	client, _, flaky := retryClient(t, 3)
+
+	flaky.fail(10, http.StatusServiceUnavailable)
+
+	if _, err := client.GetSessionState(context.Background()); err == nil {
+		t.Fatal("GetSessionState: got no error from a failing server")
+	}
+
+	want := []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond}
+	if !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits: got %v, want %v", *waits, want)
+	}
+
+	if got := flaky.seen(); got != 4 {
+		t.Errorf("requests: got %d, want 4", got)
+	}
+}
+
+func TestRetryStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var waits int
+
+	defer deluge.SetSleep(func(ctx context.Context, dur time.Duration) error {
+		waits++
+		cancel() // The caller gives up during the first wait.
+
+		return ctx.Err()
+	})()
+
+	client, _, flaky := retryClient(t, 5)
+
+	flaky.fail(10, http.StatusServiceUnavailable)
+
+	if _, err := client.GetSessionState(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("error: got %v, want %v", err, context.Canceled)
+	}
+
+	if waits != 1 || flaky.seen() != 1 {
+		t.Errorf("got %d waits and %d requests, want 1 of each", waits, flaky.seen())
+	}
+}
+
+func TestRetryTransientFailure(t *testing.T) {
+	waits := recordSleep(t)
+	client, _, flaky := retryClient(t, 3)
+
+	flaky.fail(2, http.StatusBadGateway)
+
+	if _, err := client.GetSessionState(context.Background()); err != nil {
+		t.Fatalf("GetSessionState: %v", err)
+	}
+
+	if flaky.seen() != 3 || len(*waits) != 2 {
+		t.Errorf("got %d requests and %d waits, want 3 and 2", flaky.seen(), len(*waits))
+	}
+}
+
+func TestRetryStopsAfterMaxRetries(t *testing.T) {
+	waits := recordSleep(t)
+	client, _, flaky := retryClient(t, 2)
+
+	flaky.fail(10, http.StatusInternalServerError)
+
+	if _, err := client.GetSessionState(context.Background()); err == nil {
+		t.Fatal("GetSessionState: got no error from a failing server")
+	}
+
+	if flaky.seen() != 3 || len(*waits) != 2 {
+		t.Errorf("got %d requests and %d waits, want 3 and 2", flaky.seen(), len(*waits))
+	}
+}
+
+func TestRetryPredicate(t *testing.T) {
+	waits := recordSleep(t)
+
+	server := delugetest.NewServer(delugetest.Version2)
+	defer server.Close()
+
+	flaky := newFlaky(server)
+	config := server.Config()
+	config.MaxRetries = 3
+	// Only retry a proxy's rate limit; 5xx responses are final.
+	config.RetryPredicate = func(resp *http.Response, err error) bool {
+		return err == nil && resp.StatusCode == http.StatusTooManyRequests
+	}
+
+	ctx := context.Background()
+
+	client, err := deluge.New(ctx, config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	flaky.fail(1, http.StatusServiceUnavailable)
+
+	if _, err := client.GetSessionState(ctx); err == nil {
+		t.Error("GetSessionState: got no error from a 503 the predicate rejects")
+	}
+
+	if flaky.seen() != 1 || len(*waits) != 0 {
+		t.Errorf("rejected: got %d requests and %d waits, want 1 and 0", flaky.seen(), len(*waits))
+	}
+
+	flaky.fail(1, http.StatusTooManyRequests)
+
+	if _, err := client.GetSessionState(ctx); err != nil {
+		t.Errorf("GetSessionState after a 429: %v", err)
+	}
+
+	if flaky.seen() != 2 || len(*waits) != 1 {
+		t.Errorf("accepted: got %d requests and %d waits, want 2 and 1", flaky.seen(), len(*waits))
+	}
+}
+
+func TestRetryIgnoresAuthErrors(t *testing.T) {
+	waits := recordSleep(t)
+	client, server, _ := retryClient(t, 3)
+
+	// An expired session is a Deluge error (code 1) in a 200 response, not a transport
+	// failure. It is handled by logging in again once, without retry waits.
+	server.Expire()
+
+	if _, err := client.GetSessionState(context.Background()); err != nil {
+		t.Fatalf("GetSessionState: %v", err)
+	}
+
+	if len(*waits) != 0 {
+		t.Errorf("waits: got %v, want none", *waits)
+	}
+
+	server.Lock()
+	defer server.Unlock()
+
+	calls := map[string]int{}
+	for _, method := range server.Calls {
+		calls[method]++
+	}
+
+	if calls[deluge.AuthLogin] != 2 || calls[deluge.GetSession] != 2 {
+		t.Errorf("got %d logins and %d get_session_state calls, want 2 of each (calls: %v)",
+			calls[deluge.AuthLogin], calls[deluge.GetSession], server.Calls)
+	}
+}
@@ -0,0 +1,95 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+)
+
+// Scheduler plugin grid states. The grid has one row per day (Monday first) and one column per hour.
+// The plugin stores it the other way around, as 24 rows of 7 days; GetSchedule and SetSchedule
+// transpose it.
+const (
+	ScheduleNormal  = 0 // Green: full speed.
+	ScheduleLimited = 1 // Yellow: the plugin's low speed limits apply.
+	SchedulePaused  = 2 // Red: all torrents are paused.
+)
+
+const (
+	scheduleDays  = 7
+	scheduleHours = 24
+)
+
+// GetSchedule returns the Scheduler plugin's 7x24 speed grid.
+// Returns ErrPluginNotInstalled if the Scheduler plugin is not available.
+func (d *Deluge) GetSchedule(ctx context.Context) ([][]int, error) {
+	response, err := d.Get(ctx, GetScheduler, []string{})
+	if err != nil {
+		return nil, pluginError("Scheduler", fmt.Errorf("get(GetScheduler): %w", err))
+	}
+
+	var config struct {
+		ButtonState [][]int `json:"button_state"`
+	}
+
+	if err := decodeResult(response.Result, &config); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(config): %w", err)
+	}
+
+	if len(config.ButtonState) != scheduleHours {
+		return nil, fmt.Errorf("%w: plugin sent %d hours, need %d", ErrInvalidSchedule, len(config.ButtonState), scheduleHours)
+	}
+
+	grid := make([][]int, scheduleDays)
+	for day := range grid {
+		grid[day] = make([]int, scheduleHours)
+	}
+
+	for hour, days := range config.ButtonState {
+		if len(days) != scheduleDays {
+			return nil, fmt.Errorf("%w: plugin sent %d days for hour %d, need %d",
+				ErrInvalidSchedule, len(days), hour, scheduleDays)
+		}
+
+		for day, state := range days {
+			grid[day][hour] = state
+		}
+	}
+
+	return grid, nil
+}
+
+// SetSchedule replaces the Scheduler plugin's 7x24 speed grid. Each value must be
+// ScheduleNormal, ScheduleLimited or SchedulePaused. Returns ErrInvalidSchedule
+// if the grid is the wrong size, and ErrPluginNotInstalled if the plugin is not available.
+func (d *Deluge) SetSchedule(ctx context.Context, grid [][]int) error {
+	if len(grid) != scheduleDays {
+		return fmt.Errorf("%w: %d days, need %d", ErrInvalidSchedule, len(grid), scheduleDays)
+	}
+
+	for day, hours := range grid {
+		if len(hours) != scheduleHours {
+			return fmt.Errorf("%w: day %d has %d hours, need %d", ErrInvalidSchedule, day, len(hours), scheduleHours)
+		}
+
+		for hour, state := range hours {
+			if state < ScheduleNormal || state > SchedulePaused {
+				return fmt.Errorf("%w: day %d hour %d has invalid state %d", ErrInvalidSchedule, day, hour, state)
+			}
+		}
+	}
+
+	buttonState := make([][]int, scheduleHours)
+	for hour := range buttonState {
+		buttonState[hour] = make([]int, scheduleDays)
+		for day := range grid {
+			buttonState[hour][day] = grid[day][hour]
+		}
+	}
+
+	config := map[string]interface{}{"button_state": buttonState}
+	if _, err := d.Get(ctx, SetScheduler, []interface{}{config}); err != nil {
+		return pluginError("Scheduler", fmt.Errorf("get(SetScheduler): %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// SessionStatus is the daemon's session-wide status, from SessionStatus. Rates are in bytes per second.
+// Counters holds every returned key, including libtorrent counters like "net.recv_bytes"
+// on Deluge 2.x, which are not in the struct.
+type SessionStatus struct {
+	PayloadDownloadRate    float64                    `json:"payload_download_rate"`
+	PayloadUploadRate      float64                    `json:"payload_upload_rate"`
+	DownloadRate           float64                    `json:"download_rate"`
+	UploadRate             float64                    `json:"upload_rate"`
+	DHTDownloadRate        float64                    `json:"dht_download_rate"`
+	DHTUploadRate          float64                    `json:"dht_upload_rate"`
+	DHTNodes               int64                      `json:"dht_nodes"`
+	NumPeers               int64                      `json:"num_peers"`
+	HasIncomingConnections bool                       `json:"has_incoming_connections"`
+	TotalDownload          int64                      `json:"total_download"`
+	TotalUpload            int64                      `json:"total_upload"`
+	TotalPayloadDownload   int64                      `json:"total_payload_download"`
+	TotalPayloadUpload     int64                      `json:"total_payload_upload"`
+	Counters               map[string]json.RawMessage `json:"-"`
+}
+
+// SessionStatus returns session-wide throughput and peer status from the daemon.
+// With no keys, every key in the SessionStatus struct is requested. Pass keys, like
+// libtorrent counter names, to request others; they are returned in Counters.
+func (d *Deluge) SessionStatus(ctx context.Context, keys ...string) (*SessionStatus, error) {
+	if len(keys) == 0 {
+		keys = jsonKeys(reflect.TypeOf(SessionStatus{}))
+	}
+
+	response, err := d.Get(ctx, GetSessionStat, []interface{}{keys})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetSessionStat): %w", err)
+	}
+
+	status := &SessionStatus{Counters: make(map[string]json.RawMessage)}
+	if err := decodeResult(response.Result, &status.Counters); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(counters): %w", err)
+	}
+
+	if err := decodeResult(response.Result, status); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,101 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// statsInterval is the Stats plugin history interval (in seconds) used by GetStatsHistory.
+// This is the finest interval the plugin keeps, and it is always available.
+const statsInterval = 1
+
+// StatsTotals holds all-time transfer totals from the Stats plugin.
+// The peak rates are the highest rates found in the plugin's recent history.
+type StatsTotals struct {
+	TotalUpload          int64   `json:"total_upload"`
+	TotalDownload        int64   `json:"total_download"`
+	TotalPayloadUpload   int64   `json:"total_payload_upload"`
+	TotalPayloadDownload int64   `json:"total_payload_download"`
+	PeakUploadRate       float64 `json:"-"`
+	PeakDownloadRate     float64 `json:"-"`
+}
+
+// GetStatsTotals returns all-time totals and recent peak rates from the Stats plugin.
+// Returns ErrPluginNotInstalled if the Stats plugin is not available.
+func (d *Deluge) GetStatsTotals(ctx context.Context) (*StatsTotals, error) {
+	return d.getStatsTotals(ctx, GetStatsTotal)
+}
+
+// GetStatsSessionTotals returns totals for the current daemon session from the Stats plugin.
+// Returns ErrPluginNotInstalled if the Stats plugin is not available.
+func (d *Deluge) GetStatsSessionTotals(ctx context.Context) (*StatsTotals, error) {
+	return d.getStatsTotals(ctx, GetStatsSess)
+}
+
+func (d *Deluge) getStatsTotals(ctx context.Context, method string) (*StatsTotals, error) {
+	response, err := d.Get(ctx, method, []string{})
+	if err != nil {
+		return nil, pluginError("Stats", fmt.Errorf("get(%s): %w", method, err))
+	}
+
+	var totals StatsTotals
+	if err := decodeResult(response.Result, &totals); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(totals): %w", err)
+	}
+
+	history, err := d.GetStatsHistory(ctx, []string{"upload_rate", "download_rate"})
+	if err != nil {
+		return nil, err
+	}
+
+	totals.PeakUploadRate = maxFloat(history["upload_rate"])
+	totals.PeakDownloadRate = maxFloat(history["download_rate"])
+
+	return &totals, nil
+}
+
+// GetStatsHistory returns the recent history for each requested Stats plugin key,
+// like upload_rate, download_rate or num_connections. Values are ordered newest first.
+// Returns ErrPluginNotInstalled if the Stats plugin is not available.
+func (d *Deluge) GetStatsHistory(ctx context.Context, keys []string) (map[string][]float64, error) {
+	response, err := d.Get(ctx, GetStatsHist, []interface{}{keys, statsInterval})
+	if err != nil {
+		return nil, pluginError("Stats", fmt.Errorf("get(GetStatsHist): %w", err))
+	}
+
+	// The result also contains scalar metadata keys like length and update_interval.
+	raw := make(map[string]json.RawMessage)
+	if err := decodeResult(response.Result, &raw); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(stats): %w", err)
+	}
+
+	history := make(map[string][]float64)
+
+	for _, key := range keys {
+		if _, ok := raw[key]; !ok {
+			continue
+		}
+
+		values := []float64{}
+		if err := json.Unmarshal(raw[key], &values); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(%s): %w", key, err)
+		}
+
+		history[key] = values
+	}
+
+	return history, nil
+}
+
+func maxFloat(values []float64) float64 {
+	var highest float64
+
+	for _, val := range values {
+		if val > highest {
+			highest = val
+		}
+	}
+
+	return highest
+}
@@ -0,0 +1,750 @@
+package deluge
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MoveStorage moves the data for the provided torrent hashes to a new location on the Deluge server.
+func (d *Deluge) MoveStorage(ctx context.Context, hashes []string, dest string) error {
+	if _, err := d.Get(ctx, MoveStorage, []interface{}{hashes, dest}); err != nil {
+		return fmt.Errorf("get(MoveStorage): %w", err)
+	}
+
+	return nil
+}
+
+// RemoveTorrent removes a torrent from Deluge, and optionally deletes its data.
+// Returns true if Deluge reports the torrent was removed.
+func (d *Deluge) RemoveTorrent(ctx context.Context, hash string, removeData bool) (bool, error) {
+	response, err := d.Get(ctx, RemoveTorrent, []interface{}{hash, removeData})
+	if err != nil {
+		return false, fmt.Errorf("get(RemoveTorrent): %w", err)
+	}
+
+	var removed bool
+	if err := decodeResult(response.Result, &removed); err != nil {
+		return false, fmt.Errorf("json.Unmarshal(removed): %w", err)
+	}
+
+	return removed, nil
+}
+
+// RemoveTorrents removes torrents from Deluge, and optionally deletes their data.
+// Failures are returned per hash; hashes missing from the map were removed. Deluge 2.x
+// removes every torrent in one request. Deluge 1.x has no bulk method, so each torrent
+// is removed with its own request. The error is only non-nil if the request failed.
+func (d *Deluge) RemoveTorrents(ctx context.Context, hashes []string, removeData bool) (map[string]error, error) {
+	if !d.isV1() {
+		response, err := d.Get(ctx, RemoveTorrents, []interface{}{hashes, removeData})
+		if err == nil {
+			return ParseBulkResult(response.Result), nil
+		} else if !errors.Is(err, ErrMethodNotFound) {
+			return nil, fmt.Errorf("get(RemoveTorrents): %w", err)
+		}
+	}
+
+	failed := make(map[string]error)
+
+	for _, hash := range hashes {
+		if removed, err := d.RemoveTorrent(ctx, hash, removeData); err != nil {
+			failed[hash] = err
+		} else if !removed {
+			failed[hash] = fmt.Errorf("%w: torrent not removed", ErrDelugeError)
+		}
+	}
+
+	return failed, nil
+}
+
+// RemoveTorrentSafe removes a torrent only if its name matches expectedName.
+// This guards scripts that cache hashes against removing the wrong torrent.
+// Returns ErrNameMismatch, and removes nothing, if the name does not match.
+func (d *Deluge) RemoveTorrentSafe(ctx context.Context, hash string, removeData bool, expectedName string) (bool, error) {
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, []string{"name"}})
+	if err != nil {
+		return false, fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	var status struct {
+		Name string `json:"name"`
+	}
+
+	if err := decodeResult(response.Result, &status); err != nil {
+		return false, fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	if status.Name != expectedName {
+		return false, fmt.Errorf("%w: %s: '%s' != '%s'", ErrNameMismatch, hash, status.Name, expectedName)
+	}
+
+	return d.RemoveTorrent(ctx, hash, removeData)
+}
+
+// GetSessionState returns the hash of every torrent in the Deluge session.
+func (d *Deluge) GetSessionState(ctx context.Context) ([]string, error) {
+	response, err := d.Get(ctx, GetSession, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetSession): %w", err)
+	}
+
+	hashes := []string{}
+	if err := decodeResult(response.Result, &hashes); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(hashes): %w", err)
+	}
+
+	return hashes, nil
+}
+
+// GetTorrentHashes returns the hash for every torrent in Deluge.
+// This uses the cheap GetSessionState, and falls back to requesting
+// only the hash key from every torrent if the daemon lacks that method.
+func (d *Deluge) GetTorrentHashes(ctx context.Context) ([]string, error) {
+	if hashes, err := d.GetSessionState(ctx); !errors.Is(err, ErrMethodNotFound) {
+		return hashes, err
+	}
+
+	xfers, err := d.GetXfersCompatFields(ctx, nil, []string{"hash"})
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(xfers))
+	for hash := range xfers {
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// PauseTorrent pauses a single torrent.
+func (d *Deluge) PauseTorrent(ctx context.Context, hash string) error {
+	return d.PauseTorrents(ctx, []string{hash})
+}
+
+// ResumeTorrent resumes a single torrent.
+func (d *Deluge) ResumeTorrent(ctx context.Context, hash string) error {
+	return d.ResumeTorrents(ctx, []string{hash})
+}
+
+// PauseTorrents pauses the provided torrents in a single request.
+func (d *Deluge) PauseTorrents(ctx context.Context, hashes []string) error {
+	method := PauseTorrents
+	if d.isV1() {
+		method = PauseTorrent // Deluge 1.x accepts a list on the singular method.
+	}
+
+	if _, err := d.Get(ctx, method, []interface{}{hashes}); err != nil {
+		return fmt.Errorf("get(%s): %w", method, err)
+	}
+
+	return nil
+}
+
+// ResumeTorrents resumes the provided torrents in a single request.
+func (d *Deluge) ResumeTorrents(ctx context.Context, hashes []string) error {
+	method := ResumeTorrents
+	if d.isV1() {
+		method = ResumeTorrent // Deluge 1.x accepts a list on the singular method.
+	}
+
+	if _, err := d.Get(ctx, method, []interface{}{hashes}); err != nil {
+		return fmt.Errorf("get(%s): %w", method, err)
+	}
+
+	return nil
+}
+
+// PauseSession pauses the entire libtorrent session; every torrent stops transferring.
+// Deluge 1.x has no session pause, so core.pause_all_torrents is used there, or
+// when the daemon does not know core.pause_session.
+func (d *Deluge) PauseSession(ctx context.Context) error {
+	return d.sessionMethod(ctx, PauseSession, PauseAllTorrs)
+}
+
+// ResumeSession resumes the libtorrent session after PauseSession.
+// Deluge 1.x uses core.resume_all_torrents instead; see PauseSession.
+func (d *Deluge) ResumeSession(ctx context.Context) error {
+	return d.sessionMethod(ctx, ResumeSession, ResumeAllTorrs)
+}
+
+// sessionMethod calls method, or fallback on Deluge 1.x or if the daemon lacks method.
+func (d *Deluge) sessionMethod(ctx context.Context, method, fallback string) error {
+	if !d.isV1() {
+		_, err := d.Get(ctx, method, []string{})
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, ErrMethodNotFound) {
+			return fmt.Errorf("get(%s): %w", method, err)
+		}
+	}
+
+	if _, err := d.Get(ctx, fallback, []string{}); err != nil {
+		return fmt.Errorf("get(%s): %w", fallback, err)
+	}
+
+	return nil
+}
+
+// PauseAll pauses every torrent in Deluge.
+// This uses PauseSession, which pauses the session on Deluge 2.x, and every torrent
+// on Deluge 1.x. If the daemon has neither method, the full hash list is fetched and
+// paused in one bulk request. Note that a paused session leaves each torrent's own
+// state alone, while the fallbacks pause every torrent.
+func (d *Deluge) PauseAll(ctx context.Context) error {
+	if err := d.PauseSession(ctx); !errors.Is(err, ErrMethodNotFound) {
+		return err
+	}
+
+	hashes, err := d.GetTorrentHashes(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.PauseTorrents(ctx, hashes)
+}
+
+// ResumeAll resumes every torrent in Deluge.
+// This uses ResumeSession, and falls back to resuming the full hash list
+// in one bulk request. See PauseAll for details.
+func (d *Deluge) ResumeAll(ctx context.Context) error {
+	if err := d.ResumeSession(ctx); !errors.Is(err, ErrMethodNotFound) {
+		return err
+	}
+
+	hashes, err := d.GetTorrentHashes(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.ResumeTorrents(ctx, hashes)
+}
+
+// RecheckAll forces a data recheck on every torrent in Deluge.
+// The full hash list is fetched and passed to force_recheck, which works the same on 1.x and 2.x.
+func (d *Deluge) RecheckAll(ctx context.Context) error {
+	hashes, err := d.GetTorrentHashes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.Get(ctx, ForceRecheck, []interface{}{hashes}); err != nil {
+		return fmt.Errorf("get(ForceRecheck): %w", err)
+	}
+
+	return nil
+}
+
+// GetFileProgress returns the download progress (0 to 1) of each file in a torrent, ordered by file index.
+func (d *Deluge) GetFileProgress(ctx context.Context, hash string) ([]float64, error) {
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, []string{"file_progress"}})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	var status struct {
+		FileProgress []float64 `json:"file_progress"`
+	}
+
+	if err := decodeResult(response.Result, &status); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	return status.FileProgress, nil
+}
+
+// GetTorrentStatusRaw returns the requested status keys for a torrent without decoding them.
+// Use this to read keys that are not in XferStatusCompat, like those added by plugins.
+// An empty keys list returns every key Deluge provides.
+func (d *Deluge) GetTorrentStatusRaw(ctx context.Context, hash string, keys []string) (map[string]json.RawMessage, error) {
+	if keys == nil {
+		keys = []string{}
+	}
+
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, keys})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	status := make(map[string]json.RawMessage)
+	if err := decodeResult(response.Result, &status); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	return status, nil
+}
+
+// ETAUnknown is returned by AggregateETA when torrents are downloading, but none of them have a usable ETA.
+const ETAUnknown time.Duration = -1
+
+// AggregateETA returns the longest ETA among the downloading torrents matching filter; a nil filter matches all.
+// Paused, seeding and other non-downloading torrents are ignored, as are torrents with
+// an infinite or unknown ETA (Deluge reports these as 0 or -1). Returns 0 if nothing
+// is downloading, and ETAUnknown if nothing downloading has a usable ETA.
+func (d *Deluge) AggregateETA(ctx context.Context, filter map[string]interface{}) (time.Duration, error) {
+	xfers, err := d.GetXfersCompatFields(ctx, filter, []string{"eta", "state"})
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		eta         time.Duration
+		downloading bool
+	)
+
+	for _, xfer := range xfers {
+		if xfer.State != "Downloading" {
+			continue
+		}
+
+		downloading = true
+
+		seconds, _ := xfer.Eta.Float64()
+		if seconds <= 0 {
+			continue
+		}
+
+		if dur := time.Duration(seconds * float64(time.Second)); dur > eta {
+			eta = dur
+		}
+	}
+
+	if downloading && eta == 0 {
+		return ETAUnknown, nil
+	}
+
+	return eta, nil
+}
+
+// GetActiveXfers returns the torrents that are downloading or seeding and moving data right now.
+// Server-side, Deluge's special "Active" state filter returns only torrents with
+// a non-zero download or upload payload rate. Client-side, that result is narrowed
+// to torrents in the Downloading or Seeding state, with a non-zero payload rate.
+func (d *Deluge) GetActiveXfers(ctx context.Context) (map[string]*XferStatusCompat, error) {
+	xfers, err := d.GetXfersCompatFields(ctx, map[string]interface{}{"state": "Active"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for hash, xfer := range xfers {
+		if (xfer.State != "Downloading" && xfer.State != "Seeding") ||
+			(xfer.DownloadPayloadRate == 0 && xfer.UploadPayloadRate == 0) {
+			delete(xfers, hash)
+		}
+	}
+
+	return xfers, nil
+}
+
+// GetErroredTorrents returns the error message for each torrent in the Error state, keyed by hash.
+func (d *Deluge) GetErroredTorrents(ctx context.Context) (map[string]string, error) {
+	xfers, err := d.GetXfersCompatFields(ctx, map[string]interface{}{"state": "Error"}, []string{"message"})
+	if err != nil {
+		return nil, err
+	}
+
+	errored := make(map[string]string, len(xfers))
+	for hash, xfer := range xfers {
+		errored[hash] = xfer.Message
+	}
+
+	return errored, nil
+}
+
+// ClearTorrentError force-rechecks and resumes a torrent to clear a transient error,
+// like a disk that was briefly unavailable. This is best-effort: some errors need
+// manual intervention, and the torrent returns to the Error state after the recheck.
+func (d *Deluge) ClearTorrentError(ctx context.Context, hash string) error {
+	if _, err := d.Get(ctx, ForceRecheck, []interface{}{[]string{hash}}); err != nil {
+		return fmt.Errorf("get(ForceRecheck): %w", err)
+	}
+
+	return d.ResumeTorrents(ctx, []string{hash})
+}
+
+// GetXfer returns a single transfer, with every status key, from Deluge 1.x or 2.x.
+// Returns ErrTorrentNotFound if Deluge does not have the torrent.
+func (d *Deluge) GetXfer(ctx context.Context, hash string) (*XferStatusCompat, error) {
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, []string{}})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	// Deluge returns an empty status, not an error, for an unknown hash.
+	keys := make(map[string]json.RawMessage)
+	if err := decodeResult(response.Result, &keys); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(keys): %w", err)
+	} else if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTorrentNotFound, hash)
+	}
+
+	var xfer XferStatusCompat
+	if err := json.Unmarshal(response.Result, &xfer); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(xfer): %w", err)
+	}
+
+	return &xfer, nil
+}
+
+// Exists returns true if a torrent with the provided info hash is already in Deluge.
+// Use this to skip adding duplicates. The hash is lowercased before checking.
+func (d *Deluge) Exists(ctx context.Context, hash string) (bool, error) {
+	hash = strings.ToLower(hash)
+
+	xfers, err := d.GetXfersCompatFields(ctx, map[string]interface{}{"id": []string{hash}}, []string{"hash"})
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := xfers[hash]
+
+	return ok, nil
+}
+
+// GetXfersWithDigest returns every transfer along with a digest of each transfer's hash, state and progress.
+// If the digest matches the one from your last poll, nothing you care about changed and you can skip
+// re-rendering. The digest is computed client-side; it does not save a request or bandwidth.
+func (d *Deluge) GetXfersWithDigest(ctx context.Context) (map[string]*XferStatusCompat, uint64, error) {
+	xfers, err := d.GetXfersCompatContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hashes := make([]string, 0, len(xfers))
+	for hash := range xfers {
+		hashes = append(hashes, hash)
+	}
+
+	sort.Strings(hashes)
+
+	digest := fnv.New64a()
+	for _, hash := range hashes {
+		fmt.Fprintf(digest, "%s|%s|%v\n", hash, xfers[hash].State, xfers[hash].Progress)
+	}
+
+	return xfers, digest.Sum64(), nil
+}
+
+// GetMovingTorrents returns the torrents whose data is being moved to a new location.
+// Only the name, state and progress keys are populated.
+func (d *Deluge) GetMovingTorrents(ctx context.Context) (map[string]*XferStatusCompat, error) {
+	return d.GetXfersCompatFields(ctx, map[string]interface{}{"state": "Moving"}, []string{"name", "state", "progress"})
+}
+
+// MoveToQueuePosition moves a queued torrent to a 0-based position in the queue.
+// This sends one queue_up or queue_down request per position moved, so
+// it costs O(distance) requests. Returns ErrNotQueued for unqueued torrents.
+func (d *Deluge) MoveToQueuePosition(ctx context.Context, hash string, pos int) error {
+	if pos < 0 {
+		return fmt.Errorf("%w: queue position %d", ErrInvalidValue, pos)
+	}
+
+	xfers, err := d.GetXfersCompatFields(ctx, map[string]interface{}{"id": []string{hash}}, []string{"queue"})
+	if err != nil {
+		return err
+	}
+
+	xfer, ok := xfers[hash]
+	if !ok || xfer.QueuePosition() < 0 {
+		return fmt.Errorf("%w: %s", ErrNotQueued, hash)
+	}
+
+	method, moves := QueueUp, xfer.QueuePosition()-pos
+	if moves < 0 {
+		method, moves = QueueDown, -moves
+	}
+
+	for ; moves > 0; moves-- {
+		if err := d.queue(ctx, method, []string{hash}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// QueueTop moves torrents to the top of the download queue.
+func (d *Deluge) QueueTop(ctx context.Context, hashes []string) error {
+	return d.queue(ctx, QueueTop, hashes)
+}
+
+// QueueUp moves torrents up one position in the download queue.
+func (d *Deluge) QueueUp(ctx context.Context, hashes []string) error {
+	return d.queue(ctx, QueueUp, hashes)
+}
+
+// QueueDown moves torrents down one position in the download queue.
+func (d *Deluge) QueueDown(ctx context.Context, hashes []string) error {
+	return d.queue(ctx, QueueDown, hashes)
+}
+
+// QueueBottom moves torrents to the bottom of the download queue.
+func (d *Deluge) QueueBottom(ctx context.Context, hashes []string) error {
+	return d.queue(ctx, QueueBottom, hashes)
+}
+
+// queue calls one of the core.queue_* methods. Torrents that are not queued, like seeding torrents, do not move.
+func (d *Deluge) queue(ctx context.Context, method string, hashes []string) error {
+	if _, err := d.Get(ctx, method, []interface{}{hashes}); err != nil {
+		return fmt.Errorf("get(%s): %w", method, err)
+	}
+
+	return nil
+}
+
+// XferFilter selects transfers by common fields. Empty fields are not filtered.
+// To match unlabeled torrents, pass {"label": ""} to GetXfersCompatFields instead.
+type XferFilter struct {
+	State       string
+	Label       string
+	TrackerHost string
+	Owner       string
+	IDs         []string // Torrent hashes.
+}
+
+// filter builds the Deluge filter dict from the non-empty fields.
+func (f XferFilter) filter() map[string]interface{} {
+	filter := make(map[string]interface{})
+
+	for key, val := range map[string]string{
+		"state":        f.State,
+		"label":        f.Label,
+		"tracker_host": f.TrackerHost,
+		"owner":        f.Owner,
+	} {
+		if val != "" {
+			filter[key] = val
+		}
+	}
+
+	if len(f.IDs) > 0 {
+		filter["id"] = f.IDs
+	}
+
+	return filter
+}
+
+// GetXfersMatching returns the transfers matching every non-empty field in opts.
+func (d *Deluge) GetXfersMatching(ctx context.Context, opts XferFilter) (map[string]*XferStatusCompat, error) {
+	return d.GetXfersCompatFields(ctx, opts.filter(), nil)
+}
+
+// GetXfersWithFilter returns the transfers matching every non-empty field in filter, like GetXfersContext.
+// Deluge applies the filter, so only matching transfers are sent. Use GetXfersMatching for the compat struct.
+func (d *Deluge) GetXfersWithFilter(ctx context.Context, filter XferFilter) (map[string]*XferStatus, error) {
+	xfers := make(map[string]*XferStatus)
+
+	response, err := d.Get(ctx, GetAllTorrents, statusParams(filter.filter(), nil))
+	if err != nil {
+		return nil, fmt.Errorf("get(GetAllTorrents): %w", err)
+	}
+
+	if err := decodeResult(response.Result, &xfers); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(xfers): %w", err)
+	}
+
+	return xfers, nil
+}
+
+// GetXfersFields decodes the transfers matching filter into out, a pointer to a map of hash to
+// your own struct, like *map[string]*MyStatus. Only the keys named by the struct's json tags
+// are requested, which saves a lot of time and bandwidth on large clients.
+func (d *Deluge) GetXfersFields(ctx context.Context, filter XferFilter, out interface{}) error {
+	response, err := d.Get(ctx, GetAllTorrents, statusParams(filter.filter(), StatusKeys(out)))
+	if err != nil {
+		return fmt.Errorf("get(GetAllTorrents): %w", err)
+	}
+
+	if err := decodeResult(response.Result, out); err != nil {
+		return fmt.Errorf("json.Unmarshal(xfers): %w", err)
+	}
+
+	return nil
+}
+
+// GetMoveCompletedPaths returns where each torrent moves its data when it completes, keyed by hash.
+// Torrents that do not move completed data have an empty path. Works with Deluge 1.x and 2.x.
+func (d *Deluge) GetMoveCompletedPaths(ctx context.Context) (map[string]string, error) {
+	xfers, err := d.GetXfersCompatFields(ctx, nil, []string{
+		"move_completed", "move_completed_path", "move_on_completed", "move_on_completed_path",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]string, len(xfers))
+
+	for hash, xfer := range xfers {
+		if xfer.MoveCompletedEnabled() {
+			paths[hash] = xfer.MoveCompletedLocation()
+		} else {
+			paths[hash] = ""
+		}
+	}
+
+	return paths, nil
+}
+
+// GetTorrentMetadata returns a torrent's name, embedded comment and creator, and private flag.
+// Missing metadata is returned as empty strings, not an error.
+func (d *Deluge) GetTorrentMetadata(ctx context.Context, hash string) (name, comment, creator string, private bool, err error) {
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, []string{"name", "comment", "creator", "private"}})
+	if err != nil {
+		return "", "", "", false, fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	var status struct {
+		Name    string `json:"name"`
+		Comment string `json:"comment"`
+		Creator string `json:"creator"`
+		Private bool   `json:"private"`
+	}
+
+	if err := decodeResult(response.Result, &status); err != nil {
+		return "", "", "", false, fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	return status.Name, status.Comment, status.Creator, status.Private, nil
+}
+
+// GetTorrentFile returns the .torrent file contents for a torrent, to re-seed it elsewhere.
+// Returns ErrNoTorrentFile if the daemon has no .torrent for it, like a magnet
+// that has not resolved its metadata yet. Returns ErrMethodNotFound if the
+// daemon does not provide core.get_torrent_file.
+func (d *Deluge) GetTorrentFile(ctx context.Context, hash string) ([]byte, error) {
+	response, err := d.Get(ctx, GetTorrentData, []string{hash})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetTorrentData): %w", err)
+	}
+
+	var encoded string
+	if err := decodeResult(response.Result, &encoded); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(encoded): %w", err)
+	}
+
+	if encoded == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoTorrentFile, hash)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding torrent file: %w", err)
+	}
+
+	return data, nil
+}
+
+// NoTracker is the GroupByTracker bucket for torrents without a tracker host.
+const NoTracker = "(none)"
+
+// GroupByTracker returns the hashes of all torrents grouped by tracker host.
+// Torrents with an empty tracker host are grouped under NoTracker.
+// Hashes are sorted within each group. Only the hash and tracker_host keys are requested.
+func (d *Deluge) GroupByTracker(ctx context.Context) (map[string][]string, error) {
+	xfers, err := d.GetXfersCompatFields(ctx, nil, []string{"hash", "tracker_host"})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]string)
+
+	for hash, xfer := range xfers {
+		host := xfer.TrackerHost
+		if host == "" {
+			host = NoTracker
+		}
+
+		groups[host] = append(groups[host], hash)
+	}
+
+	for _, hashes := range groups {
+		sort.Strings(hashes)
+	}
+
+	return groups, nil
+}
+
+// rangeBatchSize is how many torrents RangeXfers requests at a time.
+const rangeBatchSize = 500
+
+// RangeXfers calls fn for every torrent, with only keys populated (all keys if keys is empty).
+// Torrents are requested in batches, by hash, so only one batch is held in memory at a time;
+// use this instead of GetXfersCompatFields on very large clients. Torrents removed while
+// ranging are skipped. If fn returns an error, RangeXfers stops and returns it.
+func (d *Deluge) RangeXfers(ctx context.Context, keys []string,
+	fn func(hash string, xfer *XferStatusCompat) error,
+) error {
+	hashes, err := d.GetTorrentHashes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(hashes); start += rangeBatchSize {
+		end := start + rangeBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		xfers, err := d.GetXfersCompatFields(ctx, map[string]interface{}{"id": hashes[start:end]}, keys)
+		if err != nil {
+			return err
+		}
+
+		for _, hash := range hashes[start:end] {
+			if xfer, ok := xfers[hash]; ok {
+				if err := fn(hash, xfer); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// LibrarySize returns the total size of all torrents, and how much of that is downloaded, in bytes.
+// Only the total_size and total_done keys are requested, through RangeXfers.
+func (d *Deluge) LibrarySize(ctx context.Context) (totalSize, totalDone int64, err error) {
+	err = d.RangeXfers(ctx, []string{"total_size", "total_done"}, func(_ string, xfer *XferStatusCompat) error {
+		totalSize += int64(xfer.TotalSize)
+		totalDone += int64(xfer.TotalDone)
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return totalSize, totalDone, nil
+}
+
+// RecentlyCompleted returns the torrents that finished downloading after since.
+// The completion time is completed_time, or last_seen_complete on versions without it.
+// Torrents that never completed report 0 for both, and are not returned.
+// Only the hash, name, completed_time and last_seen_complete keys are populated.
+func (d *Deluge) RecentlyCompleted(ctx context.Context, since time.Time) (map[string]*XferStatusCompat, error) {
+	xfers, err := d.GetXfersCompatFields(ctx, nil, []string{"hash", "name", "completed_time", "last_seen_complete"})
+	if err != nil {
+		return nil, err
+	}
+
+	for hash, xfer := range xfers {
+		seconds := xfer.CompletedTime
+		if seconds <= 0 {
+			seconds = xfer.LastSeenComplete
+		}
+
+		if seconds <= 0 || !time.Unix(int64(seconds), 0).After(since) {
+			delete(xfers, hash)
+		}
+	}
+
+	return xfers, nil
+}
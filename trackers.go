@@ -0,0 +1,110 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TrackerError is a decoded tracker last_error from a torrent's status.
+type TrackerError struct {
+	URL      string
+	Value    int
+	Category string
+	Message  string // Human-readable description of Value in Category.
+}
+
+// trackerErrors maps common error values to descriptions, per error category.
+// Categories come from libtorrent and boost.asio; http errors use HTTP status text.
+//
+//nolint:gochecknoglobals,gomnd
+var trackerErrors = map[string]map[int]string{
+	"libtorrent": {
+		15: "invalid bencoding in tracker response",
+		24: "unsupported URL protocol",
+		25: "failed to parse URL",
+		31: "invalid hostname",
+		32: "invalid port",
+		33: "port blocked by port filter",
+		36: "timed out",
+	},
+	"system": {
+		32:    "broken pipe",
+		101:   "network is unreachable",
+		104:   "connection reset by peer",
+		110:   "connection timed out",
+		111:   "connection refused",
+		113:   "no route to host",
+		10051: "network is unreachable",
+		10054: "connection reset by peer",
+		10060: "connection timed out",
+		10061: "connection refused",
+		10065: "no route to host",
+	},
+	"asio.netdb": {
+		1: "host not found",
+		2: "host not found, try again",
+		3: "non-recoverable DNS failure",
+		4: "host has no address",
+	},
+	"asio.misc": {
+		1: "already open",
+		2: "end of file",
+		3: "element not found",
+	},
+}
+
+// TrackerErrors returns a decoded error for each tracker that has one.
+// Common libtorrent, system, DNS and HTTP error values get a readable Message.
+func (x *XferStatusCompat) TrackerErrors() []TrackerError {
+	errs := []TrackerError{}
+
+	for _, tracker := range x.Trackers {
+		if tracker.LastError.Value == 0 {
+			continue
+		}
+
+		errs = append(errs, TrackerError{
+			URL:      tracker.URL,
+			Value:    tracker.LastError.Value,
+			Category: tracker.LastError.Category,
+			Message:  trackerErrorMessage(tracker.LastError.Category, tracker.LastError.Value),
+		})
+	}
+
+	return errs
+}
+
+func trackerErrorMessage(category string, value int) string {
+	if category == "http" && http.StatusText(value) != "" {
+		return http.StatusText(value)
+	}
+
+	if msg, ok := trackerErrors[category][value]; ok {
+		return msg
+	}
+
+	return fmt.Sprintf("%s error %d", category, value)
+}
+
+// Tracker is a torrent tracker for SetTorrentTrackers. Trackers in lower tiers are tried first.
+type Tracker struct {
+	URL  string `json:"url"`
+	Tier int    `json:"tier"`
+}
+
+// SetTorrentTrackers replaces every tracker on a torrent with the provided list.
+// Returns ErrInvalidValue if a tracker has no URL.
+func (d *Deluge) SetTorrentTrackers(ctx context.Context, hash string, trackers []Tracker) error {
+	for _, tracker := range trackers {
+		if tracker.URL == "" {
+			return fmt.Errorf("%w: tracker with empty URL", ErrInvalidValue)
+		}
+	}
+
+	if _, err := d.Get(ctx, SetTrackers, []interface{}{hash, trackers}); err != nil {
+		return fmt.Errorf("get(SetTrackers): %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package deluge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// filterPairLen is the length of each [value, count] pair in the web UI filter lists.
+const filterPairLen = 2
+
+// uiInterval is the PollUI interval used when the provided interval is not positive.
+// The web UI itself polls every second.
+const uiInterval = time.Second
+
+// UIUpdate is everything the web UI shows, from one web.update_ui request.
+// Filters holds the sidebar counts, like Filters["state"]["Seeding"].
+// Stats holds the web UI's session stats, like upload_rate and num_connections.
+type UIUpdate struct {
+	Connected bool
+	Torrents  map[string]*XferStatusCompat
+	Filters   map[string]map[string]int
+	Stats     map[string]json.RawMessage
+	raw       map[string]json.RawMessage // Each torrent's status, to find changes.
+}
+
+// UIDelta is sent by PollUI. It holds the torrents that were added or changed since the last
+// poll, and the hashes of torrents that were removed. The first delta has every torrent.
+// A failed poll is sent as a UIDelta with Err set; polling continues at the next interval.
+type UIDelta struct {
+	Connected bool
+	Changed   map[string]*XferStatusCompat
+	Removed   []string
+	Stats     map[string]json.RawMessage
+	Err       error
+}
+
+// UpdateUI returns the connection state, sidebar filter counts, session stats, and the
+// status of the torrents matching filter, in one request. This is what the web UI polls.
+// Only the requested status keys are populated; nil keys populate every key.
+func (d *Deluge) UpdateUI(ctx context.Context, keys []string, filter map[string]interface{}) (*UIUpdate, error) {
+	if keys == nil {
+		keys = []string{}
+	}
+
+	if filter == nil {
+		filter = map[string]interface{}{}
+	}
+
+	response, err := d.Get(ctx, UpdateUI, []interface{}{keys, filter})
+	if err != nil {
+		return nil, fmt.Errorf("get(UpdateUI): %w", err)
+	}
+
+	var result struct {
+		Connected bool                           `json:"connected"`
+		Torrents  map[string]json.RawMessage     `json:"torrents"`
+		Filters   map[string][][]json.RawMessage `json:"filters"`
+		Stats     map[string]json.RawMessage     `json:"stats"`
+	}
+
+	if err := decodeResult(response.Result, &result); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(update): %w", err)
+	}
+
+	update := &UIUpdate{
+		Connected: result.Connected,
+		Torrents:  make(map[string]*XferStatusCompat, len(result.Torrents)),
+		Filters:   make(map[string]map[string]int, len(result.Filters)),
+		Stats:     result.Stats,
+		raw:       result.Torrents,
+	}
+
+	for hash, raw := range result.Torrents {
+		xfer := &XferStatusCompat{}
+		if err := json.Unmarshal(raw, xfer); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(%s): %w", hash, err)
+		}
+
+		update.Torrents[hash] = xfer
+	}
+
+	for name, pairs := range result.Filters {
+		update.Filters[name] = filterCounts(pairs)
+	}
+
+	return update, nil
+}
+
+// filterCounts converts a web UI filter list of [value, count] pairs into a map.
+func filterCounts(pairs [][]json.RawMessage) map[string]int {
+	counts := make(map[string]int, len(pairs))
+
+	for _, pair := range pairs {
+		if len(pair) < filterPairLen {
+			continue
+		}
+
+		var (
+			value string
+			count int
+		)
+
+		_ = json.Unmarshal(pair[0], &value)
+		_ = json.Unmarshal(pair[1], &count)
+		counts[value] = count
+	}
+
+	return counts
+}
+
+// PollUI calls UpdateUI every interval and sends what changed until ctx is canceled, then closes
+// the channel. Deluge sends every matching torrent each time; PollUI compares them with the
+// previous poll, so consumers only handle torrents that changed. An interval that is not
+// positive polls every second, like the web UI.
+func (d *Deluge) PollUI(ctx context.Context, keys []string, filter map[string]interface{},
+	interval time.Duration,
+) <-chan *UIDelta {
+	if interval <= 0 {
+		interval = uiInterval
+	}
+
+	deltas := make(chan *UIDelta)
+
+	go func() {
+		defer close(deltas)
+
+		last := map[string]json.RawMessage{}
+
+		for {
+			delta := &UIDelta{}
+
+			if update, err := d.UpdateUI(ctx, keys, filter); err != nil {
+				delta.Err = err
+			} else {
+				delta = update.diff(last)
+				last = update.raw
+			}
+
+			select {
+			case deltas <- delta:
+			case <-ctx.Done():
+				return
+			}
+
+			if sleep(ctx, interval) != nil {
+				return
+			}
+		}
+	}()
+
+	return deltas
+}
+
+// diff returns the torrents in the update that are new or changed since last, and the ones removed.
+func (u *UIUpdate) diff(last map[string]json.RawMessage) *UIDelta {
+	delta := &UIDelta{
+		Connected: u.Connected,
+		Changed:   make(map[string]*XferStatusCompat),
+		Removed:   []string{},
+		Stats:     u.Stats,
+	}
+
+	for hash, raw := range u.raw {
+		if old, ok := last[hash]; !ok || !bytes.Equal(old, raw) {
+			delta.Changed[hash] = u.Torrents[hash]
+		}
+	}
+
+	for hash := range last {
+		if _, ok := u.raw[hash]; !ok {
+			delta.Removed = append(delta.Removed, hash)
+		}
+	}
+
+	sort.Strings(delta.Removed)
+
+	return delta
+}
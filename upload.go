@@ -0,0 +1,143 @@
+package deluge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// addResultLen is the length of each [success, hash or error] pair returned by web.add_torrents.
+const addResultLen = 2
+
+// uploadResponse is the reply from the web UI's /upload endpoint.
+type uploadResponse struct {
+	Success bool     `json:"success"`
+	Files   []string `json:"files"`
+}
+
+// AddTorrentUpload adds a .torrent file the way the web UI does: the file is uploaded
+// to /upload as a multipart form, and then added from its temporary path with
+// web.add_torrents. Use this when a proxy rejects the large JSON body that
+// AddTorrentFile sends. opts may be nil. Returns the new torrent's hash; Deluge 1.x
+// does not report it, so the hash is empty there. This does not work with Config.RawURL
+// unless the JSON endpoint ends with /json.
+func (d *Deluge) AddTorrentUpload(ctx context.Context, filename string, contents []byte, opts *AddOptions) (string, error) {
+	path, err := d.upload(ctx, filename, contents)
+	if err != nil {
+		return "", err
+	}
+
+	torrents := []map[string]interface{}{{"path": path, "options": opts.Options()}}
+
+	response, err := d.Get(ctx, AddTorrents, []interface{}{torrents})
+	if err != nil {
+		return "", fmt.Errorf("get(AddTorrents): %w", err)
+	}
+
+	// Deluge 2.x returns a list of [success, hash or error] pairs; Deluge 1.x returns true.
+	if string(bytes.TrimSpace(response.Result)) == "true" {
+		return "", nil
+	}
+
+	var results [][]json.RawMessage
+	if json.Unmarshal(response.Result, &results) != nil || len(results) == 0 || len(results[0]) < addResultLen {
+		return "", fmt.Errorf("%w: unexpected add_torrents result: %s", ErrDelugeError, response.Result)
+	}
+
+	var success bool
+	_ = json.Unmarshal(results[0][0], &success)
+
+	if !success {
+		if hash := DuplicateHash(string(results[0][1])); hash != "" {
+			return hash, fmt.Errorf("%w: %s", ErrAlreadyAdded, hash)
+		}
+
+		return "", fmt.Errorf("%w: %s", ErrDelugeError, results[0][1])
+	}
+
+	var hash string
+	if err := decodeResult(results[0][1], &hash); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(hash): %w", err)
+	}
+
+	return hash, d.labelAdded(ctx, hash, opts)
+}
+
+// upload sends a file to the web UI's /upload endpoint, and returns its temporary path on the server.
+// If the web UI refuses the upload because the session expired, it logs in again and retries once.
+func (d *Deluge) upload(ctx context.Context, filename string, contents []byte) (string, error) {
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("creating form: %w", err)
+	}
+
+	_, _ = part.Write(contents)
+	_ = form.Close()
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	path, expired, err := d.postUpload(ctx, filename, body.Bytes(), form.FormDataContentType())
+	if !expired {
+		return path, err
+	}
+
+	d.logf("deluge: upload: %v, logging in again", err)
+
+	if err := d.LoginContext(ctx); err != nil {
+		return "", err
+	}
+
+	path, _, err = d.postUpload(ctx, filename, body.Bytes(), form.FormDataContentType())
+
+	return path, err
+}
+
+// postUpload posts a multipart form to /upload. expired is true if the web UI
+// refused it for a missing or expired session.
+func (d *Deluge) postUpload(ctx context.Context, filename string, body []byte, contentType string) (string, bool, error) {
+	uploadURL := strings.TrimSuffix(d.url, "/json") + "/upload"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(body))
+	if err != nil {
+		return "", false, fmt.Errorf("creating request: %w", err)
+	}
+
+	if d.auth != "" {
+		req.Header.Add("Authorization", d.auth)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := d.do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("d.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	reply := uploadResponse{}
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxDiscardBytes)).Decode(&reply)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "", true, fmt.Errorf("%w: upload: %s", ErrAuthFailed, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return "", false, fmt.Errorf("%w: upload: %s", ErrDelugeError, resp.Status)
+	case err != nil:
+		return "", false, fmt.Errorf("json.Unmarshal(upload): %w", err)
+	case !reply.Success || len(reply.Files) == 0:
+		return "", false, fmt.Errorf("%w: upload: %s not accepted", ErrDelugeError, filename)
+	}
+
+	return reply.Files[0], false, nil
+}
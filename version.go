@@ -0,0 +1,47 @@
+package deluge
+
+import (
+	"strconv"
+	"strings"
+)
+
+// VersionAtLeast returns true if version is the same as, or newer than, minimum.
+// Versions are compared one dotted number at a time, so "2.0.10" is newer than "2.0.9".
+// Suffixes like "dev0" or "+git" are ignored, and missing numbers count as 0.
+func VersionAtLeast(version, minimum string) bool {
+	have, want := versionNumbers(version), versionNumbers(minimum)
+
+	for len(have) < len(want) {
+		have = append(have, 0)
+	}
+
+	for idx, num := range want {
+		if have[idx] != num {
+			return have[idx] > num
+		}
+	}
+
+	return true
+}
+
+// versionNumbers returns the leading number from each dotted part of a version.
+// It stops at the first part that does not start with a number.
+func versionNumbers(version string) []int {
+	numbers := []int{}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".") {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+
+		num, err := strconv.Atoi(part[:end])
+		if err != nil {
+			break
+		}
+
+		numbers = append(numbers, num)
+	}
+
+	return numbers
+}
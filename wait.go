@@ -0,0 +1,65 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitForState polls a torrent every interval until its state matches state, like "Seeding".
+// Returns the context's error if it ends first, ErrTorrentNotFound if the torrent is removed,
+// and ErrInvalidValue if interval is not positive. When Config.KeepAlive is set, the web
+// session is checked on that schedule, and renewed if it expired, so it survives long waits.
+func (d *Deluge) WaitForState(ctx context.Context, hash, state string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: interval %v must be positive", ErrInvalidValue, interval)
+	}
+
+	lastAlive := time.Now()
+
+	for {
+		current, err := d.getState(ctx, hash)
+		if err != nil {
+			return err
+		}
+
+		if current == state {
+			return nil
+		}
+
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+
+		if d.alive > 0 && time.Since(lastAlive) >= d.alive {
+			if err := d.login(ctx, false); err != nil {
+				return fmt.Errorf("keep alive: %w", err)
+			}
+
+			lastAlive = time.Now()
+		}
+	}
+}
+
+// getState returns a single torrent's state. Deluge returns an empty status for unknown
+// hashes, so a missing state returns ErrTorrentNotFound.
+func (d *Deluge) getState(ctx context.Context, hash string) (string, error) {
+	response, err := d.Get(ctx, GetTorrentStat, []interface{}{hash, []string{"state"}})
+	if err != nil {
+		return "", fmt.Errorf("get(GetTorrentStat): %w", err)
+	}
+
+	var status struct {
+		State *string `json:"state"`
+	}
+
+	if err := decodeResult(response.Result, &status); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(status): %w", err)
+	}
+
+	if status.State == nil {
+		return "", fmt.Errorf("%w: %s", ErrTorrentNotFound, hash)
+	}
+
+	return *status.State, nil
+}
@@ -0,0 +1,37 @@
+package deluge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// GetWebConfig returns the raw web UI settings. These are separate from the daemon
+// config returned by GetConfigValues. Common keys include:
+//   - session_timeout: seconds of inactivity before a web session expires.
+//   - default_daemon: host ID the web UI connects to on start.
+//   - theme: web UI theme name.
+//   - show_session_speed, sidebar_show_zero, sidebar_multiple_filters: display toggles.
+func (d *Deluge) GetWebConfig(ctx context.Context) (map[string]json.RawMessage, error) {
+	response, err := d.Get(ctx, GetWebConfig, []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("get(GetWebConfig): %w", err)
+	}
+
+	values := make(map[string]json.RawMessage)
+	if err := decodeResult(response.Result, &values); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(values): %w", err)
+	}
+
+	return values, nil
+}
+
+// SetWebConfig sets one or more web UI settings; see GetWebConfig for common keys.
+// Raising session_timeout keeps sessions alive during long jobs.
+func (d *Deluge) SetWebConfig(ctx context.Context, values map[string]interface{}) error {
+	if _, err := d.Get(ctx, SetWebConfig, []interface{}{values}); err != nil {
+		return fmt.Errorf("get(SetWebConfig): %w", err)
+	}
+
+	return nil
+}